@@ -5,7 +5,10 @@ package nexus
 import (
 	"errors"
 	"fmt"
+	"maps"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/kercylan98/vivid"
 	"github.com/kercylan98/vivid/pkg/log"
@@ -15,6 +18,12 @@ var (
 	_ vivid.Actor            = (*Actor)(nil)
 	_ vivid.FixedOptionActor = (*Actor)(nil)
 	_ Nexus                  = (*Actor)(nil)
+	_ TakeoverNexus          = (*Actor)(nil)
+	_ CloseNexus             = (*Actor)(nil)
+	_ DeliveryNexus          = (*Actor)(nil)
+	_ GroupNexus             = (*Actor)(nil)
+	_ LookupNexus            = (*Actor)(nil)
+	_ BroadcastNexus         = (*Actor)(nil)
 )
 
 // New 构造 Nexus 实例，用于集中托管会话
@@ -22,18 +31,42 @@ var (
 // 参数：
 //   - provider：为每个新会话提供业务侧 SessionActor，不可为 nil，否则返回错误。
 //   - options：可选配置，如 WithSessionReaderProvider；未传时使用 NewOptions() 的默认值。
+//
+// 应用 options 后会调用 Options.Validate 校验配置，校验失败时返回该错误。
+// 返回值为 Nexus 接口而非具体的 *Actor：通过 Inject 注册到 ActorSystem，其余方法用于会话操作，业务可依赖该接口并在测试中替换为模拟实现。
+// 返回值同时实现 TakeoverNexus、CloseNexus 等全部扩展接口，可通过类型断言获取。
 func New(provider SessionActorProvider, options ...Option) (Nexus, error) {
 	if provider == nil {
 		return nil, errors.New("session actor provider is nil")
 	}
 
 	opts := NewOptions(options...)
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
 	a := &Actor{
 		options:  *opts,
 		provider: provider,
 	}
 	a.operator = &operator{
-		actor: a,
+		actor:    a,
+		startedC: make(chan struct{}),
+	}
+	eventBufferSize := opts.EventBufferSize
+	if eventBufferSize <= 0 {
+		eventBufferSize = defaultEventBufferSize
+	}
+	a.events = make(chan SessionEvent, eventBufferSize)
+	streamChunkSize := opts.StreamChunkSize
+	if streamChunkSize <= 0 {
+		streamChunkSize = defaultStreamChunkSize
+	}
+	a.streamBufferPool.New = func() any {
+		buf := make([]byte, streamChunkSize)
+		return &buf
+	}
+	if opts.AcceptRateLimit > 0 {
+		a.acceptLimiter = newTokenBucket(opts.AcceptRateLimit, opts.AcceptBurst)
 	}
 
 	return a, nil
@@ -45,10 +78,21 @@ type Actor struct {
 	*operator
 	options     Options
 	provider    SessionActorProvider
-	sessions    map[string]*sessionInfo // sessionId -> sessionInfo，用于替换同 id 会话与清理
-	sessionLock sync.RWMutex            // 用于保护 sessions 的读写操作
-	selfRef     vivid.ActorRef          // 自身 ActorRef，用于在 Inject 时返回
-	injectOnce  sync.Once               // 用于确保 Inject 只执行一次
+	sessions    map[string]*sessionInfo        // sessionId -> sessionInfo，用于替换同 id 会话与清理
+	refs        map[string]string              // 会话 ActorRef.String() -> sessionId，用于在 OnKilled 时 O(1) 定位会话
+	owners      map[string]map[string]struct{} // ownerKey -> sessionId 集合，由 SessionContext.SetOwner 维护
+	rooms       map[string]map[string]struct{} // room -> sessionId 集合，由 SessionContext.JoinRoom/LeaveRoom 维护
+	tags        map[string]map[string]struct{} // tag -> sessionId 集合，由 SessionContext.SetTags 维护
+	keys        map[SessionKey]string          // SessionKey -> sessionId，仅包含实现了 SessionKeyer 的会话
+	sessionLock sync.RWMutex                   // 用于保护 sessions 及其二级索引的读写操作
+	selfRef     vivid.ActorRef                 // 自身 ActorRef，用于在 Inject 时返回
+	injectOnce  sync.Once                      // 用于确保 Inject 只执行一次
+
+	acceptLimiter    *tokenBucket      // 会话接管限流器，仅在邮箱线程中访问；未启用时为 nil
+	events           chan SessionEvent // 生命周期事件通道，满时丢弃新事件
+	streamBufferPool sync.Pool         // SendStream 的分块缓冲区池，元素为 *[]byte
+	shuttingDown     atomic.Bool       // OnKill 开始后置为 true，此后到达的 Session 会被拒绝；OnLaunch 时复位
+	readBufferWarn   sync.Once         // 确保 ReadBufferGet 未生效的警告只记录一次
 }
 
 func (n *Actor) Inject(system vivid.ActorSystem, options ...vivid.ActorOption) (ref vivid.ActorRef, err error) {
@@ -76,7 +120,11 @@ func (n *Actor) OnReceive(ctx vivid.ActorContext) {
 	case *vivid.OnLaunch:
 		n.onLaunch(ctx)
 	case Session:
-		n.onSession(ctx, msg)
+		n.onSession(ctx, msg, nil)
+	case sessionBatch:
+		n.onSessions(ctx, msg)
+	case attributedSession:
+		n.onSession(ctx, msg.session, msg.attrs)
 	case *vivid.OnKilled:
 		n.onKilled(ctx, msg)
 	case *vivid.OnKill:
@@ -88,26 +136,95 @@ func (n *Actor) OnReceive(ctx vivid.ActorContext) {
 
 func (n *Actor) onLaunch(ctx vivid.ActorContext) {
 	n.operator.actorContext = ctx
-	n.reset(ctx)
+	n.operator.markStarted()
+	n.shuttingDown.Store(false)
+	infos := n.reset(ctx)
+	n.notifyReset(len(infos), ResetReasonLaunch)
 }
 
 func (n *Actor) onKill(ctx vivid.ActorContext) {
-	n.reset(ctx)
+	// 先标记关闭中，使 reset 之后才到达的 Session（如此前 TellSelf 尚未处理的消息）被拒绝，避免遗留孤立的 sessionActor
+	n.shuttingDown.Store(true)
+	infos := n.reset(ctx)
+	n.notifyReset(len(infos), ResetReasonShutdown)
+	if timeout := n.options.DrainOnKillTimeout; timeout > 0 && len(infos) > 0 {
+		if pending := awaitSessions(infos, timeout); pending > 0 {
+			ctx.Logger().Warn("drain sessions timeout", log.Int("pending_count", pending), log.Int("total_count", len(infos)))
+		}
+	}
 }
 
-func (n *Actor) reset(ctx vivid.ActorContext) {
+// reset 清空 sessions 并 Kill 所有会话，返回被 Kill 的会话列表。
+func (n *Actor) reset(ctx vivid.ActorContext) []*sessionInfo {
 	n.sessionLock.Lock()
 	defer n.sessionLock.Unlock()
 
+	n.owners = make(map[string]map[string]struct{})
+	n.rooms = make(map[string]map[string]struct{})
+	n.tags = make(map[string]map[string]struct{})
+	n.refs = make(map[string]string, n.options.InitialSessionCapacity)
+	n.keys = make(map[SessionKey]string)
 	if n.sessions == nil {
-		n.sessions = make(map[string]*sessionInfo)
-		return
+		n.sessions = make(map[string]*sessionInfo, n.options.InitialSessionCapacity)
+		return nil
+	}
+	infos := make([]*sessionInfo, 0, len(n.sessions))
+	for _, info := range n.sessions {
+		infos = append(infos, info)
 	}
-	for id, sessionRef := range n.sessions {
+	n.options.ShutdownOrder.sort(infos)
+	for _, info := range infos {
+		if resume := info.takeResume(); resume != nil {
+			_ = resume.session.Close()
+		}
+		ctx.Kill(info.ref, false, n.options.formatReason(ReasonCleanup, ""))
+		n.emitEvent(SessionEventClosed, info.GetSessionId(), nil)
+	}
+	n.sessions = make(map[string]*sessionInfo, n.options.InitialSessionCapacity)
+	return infos
+}
+
+// notifyReset 在配置了 Options.ResetHandler 时报告一次 reset 清理的会话数量与原因。
+func (n *Actor) notifyReset(count int, reason string) {
+	if handler := n.options.ResetHandler; handler != nil {
+		handler(count, reason)
+	}
+}
+
+// unregisterSession 将会话从 sessions 及所有二级索引中移除，调用方需持有 sessionLock 写锁。
+func (n *Actor) unregisterSession(id string, info *sessionInfo) {
+	if n.sessions[id] == info {
 		delete(n.sessions, id)
-		ctx.Kill(sessionRef.ref, false, "cleanup session")
 	}
-	n.sessions = make(map[string]*sessionInfo)
+	if info.ref != nil {
+		delete(n.refs, info.ref.String())
+	}
+	n.removeOwnerIndex(id, info)
+	n.removeRoomIndex(id, info)
+	n.removeTagIndex(id, info)
+	n.removeKeyIndex(id, info)
+}
+
+// awaitSessions 等待 infos 中的会话全部终止，最长等待 timeout，返回超时后仍未终止的会话数量。
+func awaitSessions(infos []*sessionInfo, timeout time.Duration) (pending int) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for i, info := range infos {
+		select {
+		case <-info.done:
+		case <-timer.C:
+			for _, rest := range infos[i:] {
+				select {
+				case <-rest.done:
+				default:
+					pending++
+				}
+			}
+			return pending
+		}
+	}
+	return 0
 }
 
 func (n *Actor) onKilled(ctx vivid.ActorContext, msg *vivid.OnKilled) {
@@ -121,30 +238,147 @@ func (n *Actor) onKilled(ctx vivid.ActorContext, msg *vivid.OnKilled) {
 	n.sessionLock.Lock()
 	defer n.sessionLock.Unlock()
 
-	for id, info := range n.sessions {
-		if info != nil && info.ref.Equals(killedRef) {
-			delete(n.sessions, id)
-			ctx.Logger().Debug("session closed", log.String("session_id", id), log.Int("online_count", len(n.sessions)))
-			break
+	id, ok := n.sessionIdByRef(killedRef)
+	if !ok {
+		return
+	}
+	info := n.sessions[id]
+	n.unregisterSession(id, info)
+	info.closeDone()
+	n.emitEvent(SessionEventClosed, id, nil)
+	ctx.Logger().Debug("session closed", log.String("session_id", id), log.Int("online_count", len(n.sessions)))
+
+	// 会话在处理恢复请求前终止，新连接已通过准入检查，直接作为新会话接管
+	if resume := info.takeResume(); resume != nil {
+		if n.shuttingDown.Load() {
+			n.rejectSession(ctx, resume.session, ErrShuttingDown)
+			return
 		}
+		n.spawnSession(ctx, resume.session, id, resume.key, resume.attrs)
 	}
 }
 
-func (n *Actor) onSession(ctx vivid.ActorContext, session Session) {
-	id := session.GetSessionId()
+// sessionIdByRef 返回 ref 对应的托管会话 ID，调用方需持有 sessionLock。
+//
+// refs 以 ActorRef.String() 为键，ActorRef 的具体类型不可比较或 ref 经序列化重建而不是同一实例时均可直接定位。
+func (n *Actor) sessionIdByRef(ref vivid.ActorRef) (string, bool) {
+	id, ok := n.refs[ref.String()]
+	if !ok {
+		return "", false
+	}
+	if _, exists := n.sessions[id]; !exists {
+		return "", false
+	}
+	return id, true
+}
+
+// onSession 接管单个会话，attrs 非空时合并到会话的元数据中（同名键覆盖 MetadataSession 提供的值）。
+func (n *Actor) onSession(ctx vivid.ActorContext, session Session, attrs map[string]any) {
+	session, id, key, ok := n.admitSession(ctx, session)
+	if !ok {
+		return
+	}
 
 	// 先行加锁，避免 OnLaunch 先执行后，还未注册到 sessions 中就推送消息
 	n.sessionLock.Lock()
 	defer n.sessionLock.Unlock()
 
+	n.spawnSession(ctx, session, id, key, attrs)
+}
+
+// onSessions 接管 TakeoverSessions 投递的一批会话：逐个完成准入检查后，在一次 sessionLock 写锁内创建所有 sessionActor 并注册。
+func (n *Actor) onSessions(ctx vivid.ActorContext, batch sessionBatch) {
+	type admitted struct {
+		session Session
+		id      string
+		key     SessionKey
+	}
+	var sessions = make([]admitted, 0, len(batch))
+	for _, session := range batch {
+		if session, id, key, ok := n.admitSession(ctx, session); ok {
+			sessions = append(sessions, admitted{session: session, id: id, key: key})
+		}
+	}
+	if len(sessions) == 0 {
+		return
+	}
+
+	n.sessionLock.Lock()
+	defer n.sessionLock.Unlock()
+
+	for _, s := range sessions {
+		n.spawnSession(ctx, s.session, s.id, s.key, nil)
+	}
+}
+
+// admitSession 对 session 执行接管前的准入检查（关闭状态、SessionKey、ID 生成、AcceptFunc、接管限流与 ID 校验），
+// 未通过时关闭 session 并返回 ok 为 false；通过时返回可能经 ID 包装后的 session 及其 ID 与 SessionKey。
+func (n *Actor) admitSession(ctx vivid.ActorContext, session Session) (_ Session, id string, key SessionKey, ok bool) {
+	if n.shuttingDown.Load() {
+		n.rejectSession(ctx, session, ErrShuttingDown)
+		return
+	}
+
+	key, err := resolveSessionKey(session)
+	if err != nil {
+		n.rejectSession(ctx, session, err)
+		return
+	}
+
+	id = session.GetSessionId()
+	if generator := n.options.SessionIdGenerator; id == "" && generator != nil {
+		id = generator()
+		session = NewSessionWithId(session, id)
+	}
+
+	if accept := n.options.AcceptFunc; accept != nil {
+		if err := accept(session); err != nil {
+			n.rejectSession(ctx, session, err)
+			return
+		}
+	}
+
+	if n.acceptLimiter != nil && !n.acceptLimiter.allow(time.Now(), 1) {
+		n.rejectSession(ctx, session, ErrAcceptRateLimited)
+		return
+	}
+
+	if validator := n.options.SessionIdValidator; validator != nil {
+		if err := validator(id); err != nil {
+			n.rejectSession(ctx, session, fmt.Errorf("invalid session id %q: %w", id, err))
+			return
+		}
+	}
+	return session, id, key, true
+}
+
+// spawnSession 为已通过准入检查的 session 创建 sessionActor 并注册到 sessions 及索引，同 ID 的旧会话会被替换，调用方需持有 sessionLock 写锁。
+func (n *Actor) spawnSession(ctx vivid.ActorContext, session Session, id string, key SessionKey, attrs map[string]any) {
+	if existing, ok := n.sessions[id]; ok && n.tryResumeSession(ctx, existing, session, key, attrs) {
+		return
+	}
+
 	sessionInfo := newSessionInfo(n.operator, session)
+	sessionInfo.key = key
+	if len(attrs) > 0 {
+		if sessionInfo.metadata == nil {
+			sessionInfo.metadata = make(map[string]any, len(attrs))
+		}
+		maps.Copy(sessionInfo.metadata, attrs)
+	}
 	sessionActor := newSessionActor(sessionInfo, n.provider, n.options)
-	ref, err := ctx.ActorOf(sessionActor)
+	var spawnOptions []vivid.ActorOption
+	if provider := n.options.SpawnOptions; provider != nil {
+		spawnOptions = provider(session)
+	}
+	ref, err := ctx.ActorOf(sessionActor, spawnOptions...)
 	if err != nil {
 		ctx.Logger().Error("session actor spawn failed", log.String("id", id), log.Any("err", err))
-		if err = session.Close(); err != nil {
-			ctx.Logger().Error("session close failed", log.String("id", id), log.Any("err", err))
+		sessionInfo.closeQueue()
+		if closeErr := sessionInfo.closeSession(); closeErr != nil {
+			ctx.Logger().Error("session close failed", log.String("id", id), log.Any("err", closeErr))
 		}
+		n.reportSessionError(session, err)
 		return
 	}
 
@@ -152,10 +386,40 @@ func (n *Actor) onSession(ctx vivid.ActorContext, session Session) {
 
 	if existing, ok := n.sessions[id]; ok {
 		ctx.Logger().Debug("close existing session", log.String("session_id", id))
-		ctx.Kill(existing.ref, false, "close existing session")
+		existing.replaced.Store(true)
+		if resume := existing.takeResume(); resume != nil {
+			// 尚未恢复的连接已被更新的连接取代
+			_ = resume.session.Close()
+		}
+		ctx.Kill(existing.ref, false, n.options.formatReason(ReasonReplaced, ""))
+		n.unregisterSession(id, existing)
+		n.emitEvent(SessionEventReplaced, id, nil)
 	}
 
 	n.sessions[id] = sessionInfo
+	n.refs[ref.String()] = id
+	if key != nil {
+		n.keys[key] = id
+	}
+	n.emitEvent(SessionEventOpened, id, nil)
 
 	ctx.Logger().Debug("session opened", log.String("session_id", id), log.Int("online_count", len(n.sessions)))
 }
+
+// rejectSession 关闭未被接管的 session，并通过 SessionErrorHandler 通知业务。
+func (n *Actor) rejectSession(ctx vivid.ActorContext, session Session, err error) {
+	id := session.GetSessionId()
+	ctx.Logger().Warn("session rejected", log.String("session_id", id), log.Any("err", err))
+	if closeErr := session.Close(); closeErr != nil {
+		ctx.Logger().Error("session close failed", log.String("session_id", id), log.Any("err", closeErr))
+	}
+	n.reportSessionError(session, err)
+}
+
+// reportSessionError 发出 SessionEventError 事件，并在配置了 SessionErrorHandler 时将会话错误交给业务处理。
+func (n *Actor) reportSessionError(session Session, err error) {
+	n.emitEvent(SessionEventError, session.GetSessionId(), err)
+	if handler := n.options.SessionErrorHandler; handler != nil {
+		handler(session, err)
+	}
+}
@@ -0,0 +1,15 @@
+package nexus
+
+import "math/rand/v2"
+
+// audit 在配置了 Options.AuditHook 时按 AuditSampleRate 采样并将入站消息交给审计回调，在邮箱线程中调用。
+func (a *sessionActor) audit(message []byte) {
+	hook := a.options.AuditHook
+	if hook == nil {
+		return
+	}
+	if rate := a.options.AuditSampleRate; rate > 0 && rate < 1 && rand.Float64() >= rate {
+		return
+	}
+	hook(a.context.GetSessionId(), message)
+}
@@ -0,0 +1,53 @@
+package nexus_test
+
+import (
+	"sync/atomic"
+	"testing"
+
+	nexus "github.com/kercylan98/vivid-nexus"
+	"github.com/kercylan98/vivid-nexus/nexustest"
+)
+
+func TestAuditSampleRate(t *testing.T) {
+	var audited atomic.Int64
+	hook := func(sessionId string, message []byte) {
+		audited.Add(1)
+	}
+	for _, tc := range []struct {
+		name    string
+		options []nexus.Option
+	}{
+		{name: "default", options: []nexus.Option{nexus.WithAuditHook(hook)}},
+		{name: "one", options: []nexus.Option{nexus.WithAuditHook(hook), nexus.WithAuditSampleRate(1)}},
+		{name: "with options", options: []nexus.Option{nexus.WithOptions(&nexus.Options{AuditHook: hook})}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			audited.Store(0)
+			var received atomic.Int64
+			n := newTestNexus(t, provide(&testActor{message: func(ctx nexus.SessionContext, message []byte) {
+				received.Add(1)
+			}}), tc.options...)
+
+			memory := nexustest.NewMemorySession("audited", nil)
+			takeover(t, n, memory)
+
+			// 逐条写入并等待处理完成，避免多条消息被合并读取
+			for i := int64(1); i <= 3; i++ {
+				if err := memory.Feed([]byte("a")); err != nil {
+					t.Fatalf("feed: %v", err)
+				}
+				eventually(t, "message received", func() bool { return received.Load() == i })
+			}
+			if got := audited.Load(); got != 3 {
+				t.Fatalf("audited %d messages, want 3", got)
+			}
+		})
+	}
+}
+
+func TestAuditSampleRateZeroRejected(t *testing.T) {
+	options := nexus.NewOptions(nexus.WithAuditHook(func(sessionId string, message []byte) {}), nexus.WithAuditSampleRate(0))
+	if err := options.Validate(); err == nil {
+		t.Fatal("audit sample rate 0 passed validation")
+	}
+}
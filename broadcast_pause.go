@@ -0,0 +1,78 @@
+package nexus
+
+import (
+	"bytes"
+	"sync"
+)
+
+// broadcastPause 保存 PauseBroadcast 的暂停状态及暂停期间按 Options.BroadcastPauseQueueSize 暂存的广播。
+type broadcastPause struct {
+	lock    sync.Mutex
+	paused  bool
+	pending []pendingBroadcast
+}
+
+// pendingBroadcast 是暂停期间被暂存的一次广播，ResumeBroadcast 时按原顺序重放。
+type pendingBroadcast struct {
+	sessionIds   []string
+	message      []byte
+	errorHandler []SendErrorHandler
+	shared       bool
+}
+
+// PauseBroadcast 暂停所有广播类发送，用于故障期间临时降低出站负载而不断开会话或重新部署。
+//
+// 暂停后 Broadcast、BroadcastShared、BroadcastContext、BroadcastResult、SendTo 以及基于它们的房间、标签、归属与
+// SessionContext.BroadcastOthers 等扇出发送不再写出：未配置 WithBroadcastPauseQueue 时直接丢弃，否则暂存至 ResumeBroadcast 时重放。
+// 逐会话的 Send、SendWait、SendAndClose 等不受影响。重复调用无副作用。
+func (o *operator) PauseBroadcast() {
+	o.broadcastPause.lock.Lock()
+	o.broadcastPause.paused = true
+	o.broadcastPause.lock.Unlock()
+}
+
+// ResumeBroadcast 恢复广播类发送，并按原顺序重放暂停期间暂存的广播，返回重放的广播数量。
+//
+// 重放在调用方 goroutine 中进行，目标为暂停时确定的会话列表，其间已关闭的会话会被跳过；
+// 重放期间其他 goroutine 发起的新广播可能先于暂存的广播写出。未暂停时调用无操作并返回 0。
+func (o *operator) ResumeBroadcast() (replayed int) {
+	o.broadcastPause.lock.Lock()
+	pending := o.broadcastPause.pending
+	o.broadcastPause.paused = false
+	o.broadcastPause.pending = nil
+	o.broadcastPause.lock.Unlock()
+
+	for _, broadcast := range pending {
+		o.fanOut(broadcast.sessionIds, broadcast.message, broadcast.errorHandler, broadcast.shared)
+	}
+	return len(pending)
+}
+
+// IsBroadcastPaused 返回广播当前是否处于 PauseBroadcast 暂停状态。
+func (o *operator) IsBroadcastPaused() bool {
+	o.broadcastPause.lock.Lock()
+	defer o.broadcastPause.lock.Unlock()
+	return o.broadcastPause.paused
+}
+
+// holdBroadcast 在广播暂停时拦截一次扇出发送：队列未满时暂存，否则丢弃。返回 false 表示未暂停，调用方应照常发送。
+func (o *operator) holdBroadcast(sessionIds []string, message []byte, errorHandler []SendErrorHandler, shared bool) (held bool) {
+	o.broadcastPause.lock.Lock()
+	defer o.broadcastPause.lock.Unlock()
+
+	if !o.broadcastPause.paused {
+		return false
+	}
+	if len(o.broadcastPause.pending) < o.actor.options.BroadcastPauseQueueSize {
+		if !shared {
+			message = bytes.Clone(message)
+		}
+		o.broadcastPause.pending = append(o.broadcastPause.pending, pendingBroadcast{
+			sessionIds:   sessionIds,
+			message:      message,
+			errorHandler: errorHandler,
+			shared:       shared,
+		})
+	}
+	return true
+}
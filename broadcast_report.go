@@ -0,0 +1,31 @@
+package nexus
+
+// BroadcastReport 汇总一次 BroadcastResult 的发送结果。
+type BroadcastReport struct {
+	Attempted int           // 实际发起写入的会话数量，不含已不存在或被跳过的会话
+	Succeeded int           // 写入成功的会话数量
+	Failed    int           // 写入失败的会话数量，等于 len(Failures)
+	Failures  []SendFailure // 每个写入失败的会话及其错误
+}
+
+// SendFailure 描述单个会话的发送失败。
+type SendFailure struct {
+	SessionId string
+	Err       error
+}
+
+// BroadcastResult 向当前所有托管会话推送 message，并返回成功与失败的汇总。
+//
+// 适用于只需统计结果、不需要在发送过程中干预的场景；需要流式处理或中止发送时请使用 Broadcast 与 SendErrorHandler。
+func (o *operator) BroadcastResult(message []byte) BroadcastReport {
+	var report BroadcastReport
+	report.Attempted = o.sendTo(o.sessionIds(), message, []SendErrorHandler{
+		func(sessionId string, sessionContext SessionContext, err error) (abort bool) {
+			report.Failures = append(report.Failures, SendFailure{SessionId: sessionId, Err: err})
+			return false
+		},
+	})
+	report.Failed = len(report.Failures)
+	report.Succeeded = report.Attempted - report.Failed
+	return report
+}
@@ -0,0 +1,79 @@
+package nexus
+
+import "time"
+
+// DrainReport 是 Drain 的结果，用于在滚动发布等场景中判断排空是否成功后再继续后续步骤。
+type DrainReport struct {
+	Total       int              // 发起关闭的会话数量
+	Closed      int              // 在超时前完成关闭的会话数量
+	ForceKilled int              // 超时时尚未开始优雅关闭，改为强制关闭（跳过 OnDisconnected 与缓冲写出）的会话数量
+	Closing     int              // 超时时已在优雅关闭中（如 OnDisconnected 阻塞）的会话数量，其连接由优雅关闭流程在完成后关闭
+	Errors      map[string]error // 关闭底层 Session 失败的会话 ID 及其错误，无失败时为 nil
+}
+
+// Succeeded 报告本次排空是否所有会话均在超时前完成关闭且未产生关闭错误。
+func (r DrainReport) Succeeded() bool {
+	return r.ForceKilled == 0 && r.Closing == 0 && len(r.Errors) == 0
+}
+
+// Drain 优雅关闭当前托管的所有会话并等待其完成关闭，返回逐会话结果的汇总。
+//
+// 会话按 Options.ShutdownOrder 的顺序发出关闭，最多等待 timeout（小于等于 0 时不限制）。超时时仍未完成关闭的会话分两种情况：
+//   - 尚未开始优雅关闭（如邮箱被阻塞的 OnMessage 占用）：改为强制关闭，其邮箱处理到 Kill 时跳过 OnDisconnected 与缓冲写出；
+//     由于优雅关闭不会再执行，调用方 goroutine 直接关闭其底层 Session 以唤醒阻塞在 I/O 上的回调，计入 ForceKilled。
+//   - 已在优雅关闭中（如 OnDisconnected 阻塞）：不再强制关闭，以免在写出剩余缓冲期间关闭连接，计入 Closing，连接在优雅关闭完成后关闭。
+//
+// Drain 不会阻止新会话接入，调用前应先停止接入层（如关闭监听）；开始后新接管的会话不在本次统计范围内。
+// Drain 等待会话的邮箱完成关闭，不可在会话自身的回调中调用。
+func (o *operator) Drain(timeout time.Duration) DrainReport {
+	o.actor.sessionLock.Lock()
+	infos := make([]*sessionInfo, 0, len(o.actor.sessions))
+	for _, info := range o.actor.sessions {
+		infos = append(infos, info)
+	}
+	o.actor.options.ShutdownOrder.sort(infos)
+	for _, info := range infos {
+		o.actorContext.Kill(info.ref, false, o.actor.options.formatReason(ReasonDrain, ""))
+	}
+	o.actor.sessionLock.Unlock()
+
+	var deadline <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	report := DrainReport{Total: len(infos)}
+	expired := false
+	for _, info := range infos {
+		if !expired {
+			select {
+			case <-info.done:
+			case <-deadline:
+				expired = true
+			}
+		}
+		select {
+		case <-info.done:
+			report.Closed++
+		default:
+			if !info.markForceClose() {
+				report.Closing++
+				continue
+			}
+			report.ForceKilled++
+		}
+		if info.detached.Load() {
+			continue
+		}
+		// 已完成关闭的会话返回首次关闭的结果，强制关闭的会话在此关闭底层 Session
+		if err := info.closeSession(); err != nil {
+			if report.Errors == nil {
+				report.Errors = make(map[string]error)
+			}
+			report.Errors[info.GetSessionId()] = err
+		}
+	}
+	return report
+}
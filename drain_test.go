@@ -0,0 +1,97 @@
+package nexus_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	nexus "github.com/kercylan98/vivid-nexus"
+	"github.com/kercylan98/vivid-nexus/nexustest"
+)
+
+// closeErrorSession 的 Close 在关闭底层 MemorySession 后返回 err。
+type closeErrorSession struct {
+	*nexustest.MemorySession
+	err error
+}
+
+func (s *closeErrorSession) Close() error {
+	_ = s.MemorySession.Close()
+	return s.err
+}
+
+// TestDrainTimeout 验证 Drain 超时时只强制关闭尚未开始优雅关闭的会话，已在 OnDisconnected 中阻塞的会话不会被强制关闭。
+func TestDrainTimeout(t *testing.T) {
+	errClose := errors.New("close failed")
+	release := make(chan struct{})
+	busyEntered := make(chan struct{})
+	var lock sync.Mutex
+	disconnected := make(map[string]bool)
+	n := newTestNexus(t, provide(&testActor{
+		message: func(ctx nexus.SessionContext, message []byte) {
+			if ctx.GetSessionId() == "busy" {
+				close(busyEntered)
+				<-release
+			}
+		},
+		disconnected: func(ctx nexus.SessionContext) {
+			lock.Lock()
+			disconnected[ctx.GetSessionId()] = true
+			lock.Unlock()
+			if ctx.GetSessionId() == "slow" {
+				<-release
+			}
+		},
+	}))
+
+	fast := nexustest.NewMemorySession("fast", nil)
+	slow := nexustest.NewMemorySession("slow", nil)
+	busy := &closeErrorSession{MemorySession: nexustest.NewMemorySession("busy", nil), err: errClose}
+	for _, session := range []nexus.Session{fast, slow, busy} {
+		takeover(t, n, session)
+	}
+	// busy 的邮箱被阻塞的 OnMessage 占用，Kill 无法开始处理
+	if err := busy.Feed([]byte("block")); err != nil {
+		t.Fatalf("feed: %v", err)
+	}
+	<-busyEntered
+	slowDone, busyDone := n.Done("slow"), n.Done("busy")
+
+	report := n.(nexus.CloseNexus).Drain(100 * time.Millisecond)
+	if report.Total != 3 || report.Closed != 1 || report.ForceKilled != 1 || report.Closing != 1 {
+		t.Fatalf("report = %+v, want total 3, closed 1, force killed 1, closing 1", report)
+	}
+	if len(report.Errors) != 1 || !errors.Is(report.Errors["busy"], errClose) {
+		t.Fatalf("report errors = %v, want only busy: %v", report.Errors, errClose)
+	}
+	if report.Succeeded() {
+		t.Fatal("report succeeded with force killed and closing sessions")
+	}
+	if !fast.Closed() || !busy.Closed() {
+		t.Fatal("closed or force killed session left open")
+	}
+	if slow.Closed() {
+		t.Fatal("session closed while OnDisconnected was still running")
+	}
+
+	close(release)
+	for _, done := range []<-chan struct{}{slowDone, busyDone} {
+		select {
+		case <-done:
+		case <-time.After(testTimeout):
+			t.Fatal("timeout waiting for sessions to finish closing")
+		}
+	}
+	if !slow.Closed() {
+		t.Fatal("slow session not closed after OnDisconnected returned")
+	}
+	lock.Lock()
+	defer lock.Unlock()
+	if disconnected["busy"] {
+		t.Fatal("force killed session received OnDisconnected")
+	}
+	if !disconnected["fast"] || !disconnected["slow"] {
+		t.Fatalf("disconnected = %v, want fast and slow", disconnected)
+	}
+}
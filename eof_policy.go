@@ -0,0 +1,130 @@
+package nexus
+
+import (
+	"io"
+	"time"
+
+	"github.com/kercylan98/vivid"
+	"github.com/kercylan98/vivid/pkg/log"
+)
+
+// EOFPolicy 描述读循环读到 EOF（对端正常关闭）时如何处理会话。
+type EOFPolicy uint8
+
+const (
+	// EOFPolicyClose 读到 EOF 即关闭会话，为默认策略。
+	EOFPolicyClose EOFPolicy = iota
+	// EOFPolicyGrace 读到 EOF 后会话进入宽限期：sessionActor 及其状态保留，同 ID 的新连接在宽限期内被接管时
+	// 恢复原会话而不是替换它；宽限期结束仍未恢复则关闭会话。
+	EOFPolicyGrace
+)
+
+// ResumedSessionActor 是 SessionActor 的可选扩展。启用 EOFPolicyGrace 时，会话在宽限期内被同 ID 的新连接恢复后调用 OnResumed，
+// 此时 SessionContext 已绑定新连接，可在此补发断线期间的状态。未实现时恢复过程不会调用任何回调。
+type ResumedSessionActor interface {
+	SessionActor
+	OnResumed(ctx SessionContext)
+}
+
+// sessionSuspend 由读循环在 EOFPolicyGrace 下读到 EOF 时投递到会话邮箱，使会话进入宽限期。
+type sessionSuspend struct{}
+
+// sessionResume 携带用于恢复会话的新连接及其接管参数。Nexus Actor 将其记录到 sessionInfo.resume 后投递到会话邮箱，
+// sessionActor 与 Nexus Actor（会话在处理该消息前终止时）以 CompareAndSwap 竞争取走，保证新连接只被处理一次。
+type sessionResume struct {
+	session Session
+	key     SessionKey
+	attrs   map[string]any
+}
+
+// onSuspend 使会话进入宽限期：标记为未就绪并启动宽限定时器，到期仍未恢复则 Kill 本会话。
+func (a *sessionActor) onSuspend(ctx vivid.ActorContext) {
+	if a.closed.Load() {
+		return
+	}
+	info := a.context.sessionInfo
+	info.ready.Store(false)
+	info.suspended.Store(true)
+
+	grace := a.options.EOFGracePeriod
+	a.context.Logger().Debug("session suspended", log.Any("grace", grace))
+	a.graceTimer = time.AfterFunc(grace, func() {
+		// 与 Nexus Actor 竞争 suspended，新连接已被交给本会话恢复时放弃关闭
+		if a.closed.Load() || !info.suspended.CompareAndSwap(true, false) {
+			return
+		}
+		ctx.Kill(ctx.Ref(), false, a.options.formatReason(ReasonResumeExpired, ""))
+	})
+}
+
+// onResume 以新连接替换处于宽限期的会话的底层连接，并重新启动读循环。
+// 会话已关闭或新连接已被 Nexus Actor 取走时无操作，此时由 Nexus Actor 在会话终止后将新连接作为新会话接管。
+func (a *sessionActor) onResume(ctx vivid.ActorContext, msg *sessionResume) {
+	info := a.context.sessionInfo
+	if a.closed.Load() || !info.resume.CompareAndSwap(msg, nil) {
+		return
+	}
+	if a.graceTimer != nil {
+		a.graceTimer.Stop()
+	}
+	session := msg.session
+
+	reader, err := a.options.SessionReaderProvider.Provide(session)
+	if err != nil || reader == nil {
+		a.context.Logger().Error("session resume failed", log.Any("err", err))
+		_ = session.Close()
+		ctx.Kill(ctx.Ref(), false, a.options.formatReason(ReasonResumeExpired, "session reader unavailable"))
+		return
+	}
+	if !a.options.bindReadBuffer(reader) {
+		a.context.operator.actor.warnReadBufferUnused()
+	}
+
+	// 读循环已因 EOF 退出，等待其完全结束后再替换 Reader 与底层 Session
+	a.readers.Wait()
+	a.readerLock.Lock()
+	retired := append(a.retiredReaders, a.reader)
+	a.retiredReaders = nil
+	a.reader = reader
+	a.readerLock.Unlock()
+	for _, previous := range retired {
+		if closer, ok := previous.(io.Closer); ok {
+			_ = closer.Close()
+		}
+	}
+
+	// 持有写锁替换，确保进行中的写出不会跨越新旧连接
+	info.writeLock.Lock()
+	previous := info.session()
+	info.current.Store(&session)
+	info.writeLock.Unlock()
+	_ = previous.Close()
+
+	a.bindSession()
+	info.ready.Store(true)
+	info.operator.actor.emitEvent(SessionEventResumed, info.GetSessionId(), nil)
+	a.context.Logger().Debug("session resumed")
+	if actor, ok := a.externalSessionActor.(ResumedSessionActor); ok {
+		actor.OnResumed(a.context)
+	}
+	a.readers.Add(1)
+	go a.readLoop(ctx)
+}
+
+// tryResumeSession 尝试将 session 交给处于宽限期的 existing 以恢复会话，宽限期已结束（existing 不再处于宽限期）时返回 false，
+// 此时调用方应按新会话接管 session。调用方需持有 sessionLock 写锁。
+func (n *Actor) tryResumeSession(ctx vivid.ActorContext, existing *sessionInfo, session Session, key SessionKey, attrs map[string]any) bool {
+	// 与宽限定时器竞争 suspended，胜出后宽限定时器不再关闭 existing
+	if !existing.suspended.CompareAndSwap(true, false) {
+		return false
+	}
+	resume := &sessionResume{session: session, key: key, attrs: attrs}
+	existing.resume.Store(resume)
+	ctx.Tell(existing.ref, resume)
+	return true
+}
+
+// takeResume 取走 info 尚未被 sessionActor 处理的恢复请求，无则返回 nil。调用方需持有 sessionLock 写锁。
+func (info *sessionInfo) takeResume() *sessionResume {
+	return info.resume.Swap(nil)
+}
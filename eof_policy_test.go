@@ -0,0 +1,95 @@
+package nexus_test
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	nexus "github.com/kercylan98/vivid-nexus"
+	"github.com/kercylan98/vivid-nexus/nexustest"
+)
+
+// resumeActor 记录各回调的调用次数，并实现 ResumedSessionActor。
+type resumeActor struct {
+	testActor
+	connected, disconnected, resumed atomic.Int32
+	received                         atomic.Value // 最近一次收到的消息
+}
+
+func newResumeActor() *resumeActor {
+	a := &resumeActor{}
+	a.testActor.connected = func(ctx nexus.SessionContext) { a.connected.Add(1) }
+	a.testActor.disconnected = func(ctx nexus.SessionContext) { a.disconnected.Add(1) }
+	a.testActor.message = func(ctx nexus.SessionContext, message []byte) { a.received.Store(string(message)) }
+	return a
+}
+
+func (a *resumeActor) OnResumed(ctx nexus.SessionContext) {
+	a.resumed.Add(1)
+}
+
+func TestEOFGraceResume(t *testing.T) {
+	actor := newResumeActor()
+	n := newTestNexus(t, provide(actor), nexus.WithEOFPolicy(nexus.EOFPolicyGrace, time.Minute))
+
+	first := nexustest.NewMemorySession("grace", nil)
+	takeover(t, n, first)
+	first.CloseWrite()
+	eventually(t, "session suspended", func() bool {
+		snapshots := n.Snapshot()
+		return len(snapshots) == 1 && !snapshots[0].Ready
+	})
+
+	second := nexustest.NewMemorySession("grace", nil)
+	takeover(t, n, second)
+	eventually(t, "OnResumed", func() bool { return actor.resumed.Load() == 1 })
+	eventually(t, "previous connection closed", first.Closed)
+	if got := actor.connected.Load(); got != 1 {
+		t.Fatalf("OnConnected called %d times, want 1", got)
+	}
+	if got := actor.disconnected.Load(); got != 0 {
+		t.Fatalf("OnDisconnected called %d times, want 0", got)
+	}
+
+	// 恢复后的读写均作用于新连接
+	if err := second.Feed([]byte("ping")); err != nil {
+		t.Fatalf("feed: %v", err)
+	}
+	eventually(t, "message from resumed connection", func() bool { return actor.received.Load() == "ping" })
+	if err := n.SendWait("grace", []byte("pong")); err != nil {
+		t.Fatalf("send to resumed session: %v", err)
+	}
+	if written := second.Written(); len(written) != 1 || string(written[0]) != "pong" {
+		t.Fatalf("resumed connection written %q, want [pong]", written)
+	}
+}
+
+func TestEOFGraceExpiredReconnect(t *testing.T) {
+	actor := newResumeActor()
+	n := newTestNexus(t, provide(actor), nexus.WithEOFPolicy(nexus.EOFPolicyGrace, 20*time.Millisecond))
+
+	first := nexustest.NewMemorySession("grace", nil)
+	takeover(t, n, first)
+	first.CloseWrite()
+	eventually(t, "grace expired", func() bool { return actor.disconnected.Load() == 1 })
+	eventually(t, "previous connection closed", first.Closed)
+
+	// 宽限期结束后同 ID 的新连接作为新会话接管
+	second := nexustest.NewMemorySession("grace", nil)
+	takeover(t, n, second)
+	if got := actor.connected.Load(); got != 2 {
+		t.Fatalf("OnConnected called %d times, want 2", got)
+	}
+	if got := actor.resumed.Load(); got != 0 {
+		t.Fatalf("OnResumed called %d times, want 0", got)
+	}
+	if second.Closed() {
+		t.Fatal("new connection closed after grace period expired")
+	}
+	if err := n.SendWait("grace", []byte("hello")); err != nil {
+		t.Fatalf("send to new session: %v", err)
+	}
+	if written := second.Written(); len(written) != 1 || string(written[0]) != "hello" {
+		t.Fatalf("new connection written %q, want [hello]", written)
+	}
+}
@@ -0,0 +1,61 @@
+package nexus
+
+import "errors"
+
+var (
+	// ErrSessionNotFound 表示指定 sessionId 的会话不存在或已被移除。
+	ErrSessionNotFound = errors.New("session not found")
+
+	// ErrSessionClosed 表示会话已关闭，无法继续完成本次操作。
+	ErrSessionClosed = errors.New("session closed")
+
+	// ErrSessionClosing 表示会话已进入关闭流程（如 SendAndClose 已写出最后一条消息，或关闭时剩余缓冲已写出），不再接受新的写入。
+	ErrSessionClosing = errors.New("session closing")
+
+	// ErrDetachUnsupported 表示会话的底层 Session 未实现 ReadDeadlineSession，无法在不关闭连接的情况下中断读取并分离。
+	ErrDetachUnsupported = errors.New("session does not support read deadline, cannot detach")
+
+	// ErrShuttingDown 表示 Nexus Actor 正在关闭，不再接管新的会话。
+	ErrShuttingDown = errors.New("nexus shutting down")
+
+	// ErrAckTimeout 表示 WaitAck 在超时时间内未收到客户端对指定序号的确认。
+	ErrAckTimeout = errors.New("session ack timeout")
+
+	// ErrAckNotConfigured 表示调用 SendWithAck 时未通过 WithAck 配置 AckEncoder 与 AckExtractor。
+	ErrAckNotConfigured = errors.New("ack is not configured, use WithAck")
+
+	// ErrRateLimited 表示会话的出站速率超出了 Options.OutboundRateLimit，且未启用出站队列，本次发送被拒绝。
+	ErrRateLimited = errors.New("session outbound rate limited")
+
+	// ErrBroadcastPaused 表示广播已被 PauseBroadcast 暂停，本次广播未写出（可能已按 Options.BroadcastPauseQueueSize 暂存）。
+	ErrBroadcastPaused = errors.New("broadcast paused")
+
+	// ErrSendTimeout 表示 Broadcast/SendTo 向某会话写出的耗时超过了 Options.BroadcastSendTimeout，该会话被跳过；
+	// 或 SendStream 的耗时超过了 Options.StreamTimeout。
+	ErrSendTimeout = errors.New("session send timeout")
+
+	// ErrNilStreamReader 表示调用 SendStream 时传入的 io.Reader 为 nil。
+	ErrNilStreamReader = errors.New("stream reader is nil")
+
+	// ErrReaderSwapUnsupported 表示会话未运行逐条读取的读循环（启用了 MessageBatchSize 或禁用了读循环），无法替换 SessionReader。
+	ErrReaderSwapUnsupported = errors.New("session reader swap unsupported")
+
+	// ErrNotStarted 表示 Nexus Actor 尚未启动（未收到 OnLaunch），暂时无法处理本次操作。
+	ErrNotStarted = errors.New("nexus not started")
+
+	// ErrNoDataYet 由 SessionReader 返回，表示暂时没有可读数据但连接仍然有效。
+	// readLoop 收到该错误后会等待 Options.ReadRetryInterval 再重试，而不会关闭会话。
+	ErrNoDataYet = errors.New("session reader: no data yet")
+
+	// ErrSendQueueFull 表示启用出站队列时，会话的待写出消息数量已达到 Options.SendQueueSize。
+	ErrSendQueueFull = errors.New("session send queue full")
+
+	// ErrReadTimeout 表示 NewTimeoutReaderProvider 包装的 SessionReader 单次 Read 超过了配置的超时时间。
+	ErrReadTimeout = errors.New("session read timeout")
+
+	// ErrFrameTooLarge 表示分帧 SessionReader 读到的帧长度超出了配置的上限。
+	ErrFrameTooLarge = errors.New("session frame too large")
+
+	// ErrAcceptRateLimited 表示新会话到达速率超出了 Options.AcceptRateLimit，会话被拒绝，客户端应稍后重试。
+	ErrAcceptRateLimited = errors.New("session accept rate limited, try again later")
+)
@@ -45,7 +45,9 @@ func main() {
 		session := session.NewSession(sessionId, ws, map[string]any{
 			"nickname": c.Query("nickname"),
 		})
-		nexusInstance.TakeoverSession(session)
+		if err = nexusInstance.TakeoverSession(session); err != nil {
+			_ = session.Close()
+		}
 	})
 
 	if err := router.Run(":8080"); err != nil {
@@ -56,7 +58,7 @@ func main() {
 func initNexusActor() nexus.Nexus {
 	nexusActor, err := nexus.New(nexus.SessionActorProviderFN(func() (nexus.SessionActor, error) {
 		return new(session.Actor), nil
-	}))
+	}), nexus.WithMaxMessageSize(64*1024))
 	if err != nil {
 		panic(err)
 	}
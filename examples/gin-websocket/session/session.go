@@ -3,6 +3,7 @@ package session
 import (
 	"io"
 	"sync/atomic"
+	"time"
 
 	"github.com/gorilla/websocket"
 	nexus "github.com/kercylan98/vivid-nexus"
@@ -11,6 +12,10 @@ import (
 var (
 	_ nexus.Session         = (*Session)(nil)
 	_ nexus.MetadataSession = (*Session)(nil)
+	_ nexus.FrameKindWriter = (*Session)(nil)
+	_ nexus.ControlSession  = (*Session)(nil)
+	_ nexus.ReadLimiter     = (*Session)(nil)
+	_ nexus.CodedCloser     = (*Session)(nil)
 )
 
 func NewSession(sessionId string, conn *websocket.Conn, metadata map[string]any) *Session {
@@ -22,20 +27,38 @@ func NewSession(sessionId string, conn *websocket.Conn, metadata map[string]any)
 }
 
 type Session struct {
-	sessionId string
-	conn      *websocket.Conn
-	closed    atomic.Bool
-	metadata  map[string]any
+	sessionId  string
+	conn       *websocket.Conn
+	closed     atomic.Bool
+	closeFrame atomic.Pointer[closeFrame]
+	metadata   map[string]any
 }
 
+// closeFrame 是关闭时写出的 WebSocket 关闭码与原因。
+type closeFrame struct {
+	code   int
+	reason string
+}
+
+// Close 写出关闭帧后关闭连接；此前通过 CloseWithCode 指定了关闭码时使用该关闭码，否则使用 CloseNormalClosure。
 func (s *Session) Close() error {
 	if !s.closed.CompareAndSwap(false, true) {
 		return nil
 	}
-	_ = s.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	frame := closeFrame{code: websocket.CloseNormalClosure}
+	if stored := s.closeFrame.Load(); stored != nil {
+		frame = *stored
+	}
+	_ = s.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(frame.code, frame.reason), time.Now().Add(time.Second))
 	return s.conn.Close()
 }
 
+// CloseWithCode 以指定的 WebSocket 关闭码与原因关闭连接，如 websocket.ClosePolicyViolation。
+func (s *Session) CloseWithCode(code int, reason string) error {
+	s.closeFrame.CompareAndSwap(nil, &closeFrame{code: code, reason: reason})
+	return s.Close()
+}
+
 func (s *Session) GetSessionId() string {
 	return s.sessionId
 }
@@ -57,6 +80,37 @@ func (s *Session) Write(p []byte) (n int, err error) {
 	return len(p), s.conn.WriteMessage(websocket.TextMessage, p)
 }
 
+func (s *Session) WriteFrame(kind nexus.FrameKind, p []byte) (n int, err error) {
+	messageType := websocket.TextMessage
+	if kind == nexus.FrameKindBinary {
+		messageType = websocket.BinaryMessage
+	}
+	return len(p), s.conn.WriteMessage(messageType, p)
+}
+
+func (s *Session) SetControlHandler(handler func(kind nexus.ControlKind, payload []byte)) {
+	s.conn.SetPingHandler(func(appData string) error {
+		handler(nexus.ControlPing, []byte(appData))
+		return nil
+	})
+	s.conn.SetPongHandler(func(appData string) error {
+		handler(nexus.ControlPong, []byte(appData))
+		return nil
+	})
+}
+
+func (s *Session) WriteControl(kind nexus.ControlKind, payload []byte) error {
+	messageType := websocket.PingMessage
+	if kind == nexus.ControlPong {
+		messageType = websocket.PongMessage
+	}
+	return s.conn.WriteControl(messageType, payload, time.Now().Add(time.Second))
+}
+
+func (s *Session) SetReadLimit(limit int64) {
+	s.conn.SetReadLimit(limit)
+}
+
 func (s *Session) Metadata() map[string]any {
 	return s.metadata
 }
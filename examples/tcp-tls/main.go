@@ -0,0 +1,102 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"flag"
+	"os"
+
+	"github.com/kercylan98/vivid"
+	nexus "github.com/kercylan98/vivid-nexus"
+	"github.com/kercylan98/vivid-nexus/examples/tcp-tls/session"
+	"github.com/kercylan98/vivid/pkg/bootstrap"
+	"github.com/kercylan98/vivid/pkg/log"
+)
+
+// 启动后可使用 openssl 连接验证双向 TLS：
+//
+//	openssl s_client -connect 127.0.0.1:8443 -servername example.local -cert client.pem -key client-key.pem -CAfile ca.pem
+var (
+	addr     = flag.String("addr", ":8443", "listen address")
+	certFile = flag.String("cert", "server.pem", "server certificate file")
+	keyFile  = flag.String("key", "server-key.pem", "server private key file")
+	caFile   = flag.String("ca", "ca.pem", "client CA certificate file")
+)
+
+func main() {
+	flag.Parse()
+
+	nexusInstance := initNexusActor()
+	actorSystem := initActorSystem()
+
+	if _, err := nexusInstance.Inject(actorSystem); err != nil {
+		panic(err)
+	}
+
+	listener, err := tls.Listen("tcp", *addr, initTLSConfig())
+	if err != nil {
+		panic(err)
+	}
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			panic(err)
+		}
+		go handleConn(nexusInstance, conn.(*tls.Conn))
+	}
+}
+
+func handleConn(nexusInstance nexus.Nexus, conn *tls.Conn) {
+	// 先完成握手，确保交给 Nexus 时 ConnectionState 中已包含对端证书与 SNI
+	if err := conn.Handshake(); err != nil {
+		_ = conn.Close()
+		return
+	}
+
+	s := session.NewSession(conn.RemoteAddr().String(), conn)
+	if err := nexusInstance.TakeoverSession(s); err != nil {
+		_ = s.Close()
+	}
+}
+
+func initNexusActor() nexus.Nexus {
+	nexusActor, err := nexus.New(nexus.SessionActorProviderFN(func() (nexus.SessionActor, error) {
+		return new(session.Actor), nil
+	}), nexus.WithSessionReaderProvider(nexus.SessionReaderProviderFN(func(s nexus.Session) (nexus.SessionReader, error) {
+		return nexus.ChainReaders(s, nexus.NewDelimiterStage([]byte("\n"), 64*1024))
+	})))
+	if err != nil {
+		panic(err)
+	}
+	return nexusActor
+}
+
+func initActorSystem() vivid.ActorSystem {
+	system := bootstrap.NewActorSystem(vivid.WithActorSystemLogger(log.NewTextLogger(log.WithLevel(log.LevelDebug))))
+	if err := system.Start(); err != nil {
+		panic(err)
+	}
+	return system
+}
+
+func initTLSConfig() *tls.Config {
+	cert, err := tls.LoadX509KeyPair(*certFile, *keyFile)
+	if err != nil {
+		panic(err)
+	}
+	caPEM, err := os.ReadFile(*caFile)
+	if err != nil {
+		panic(err)
+	}
+	clientCAs := x509.NewCertPool()
+	if !clientCAs.AppendCertsFromPEM(caPEM) {
+		panic("invalid client ca file: " + *caFile)
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+		MinVersion:   tls.VersionTLS12,
+	}
+}
@@ -0,0 +1,44 @@
+package session
+
+import (
+	"github.com/kercylan98/vivid/pkg/log"
+
+	nexus "github.com/kercylan98/vivid-nexus"
+)
+
+var (
+	_ nexus.SessionActor = (*Actor)(nil)
+)
+
+type Actor struct {
+}
+
+func (a *Actor) OnConnected(ctx nexus.SessionContext) {
+	state, ok := ctx.TLSConnectionState()
+	if !ok || len(state.PeerCertificates) == 0 {
+		ctx.Send([]byte("client certificate required\n"))
+		ctx.Close()
+		return
+	}
+
+	peer := state.PeerCertificates[0].Subject.CommonName
+	ctx.Logger().Info("tls session connected", log.String("peer", peer), log.String("server_name", state.ServerName))
+	ctx.Send([]byte("hello " + peer + ", server name: " + state.ServerName + "\n"))
+	ctx.Send([]byte("commands: close, other messages will be echoed\n"))
+}
+
+func (a *Actor) OnDisconnected(ctx nexus.SessionContext) {
+}
+
+// OnMessage 收到的 message 已由 NewDelimiterStage 去除结尾的换行符，回显时需补回。
+func (a *Actor) OnMessage(ctx nexus.SessionContext, message []byte) {
+	switch string(message) {
+	case "close":
+		ctx.Close()
+	default:
+		echo := make([]byte, 0, len(message)+1)
+		echo = append(echo, message...)
+		echo = append(echo, '\n')
+		ctx.Send(echo)
+	}
+}
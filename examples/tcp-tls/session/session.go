@@ -0,0 +1,51 @@
+package session
+
+import (
+	"crypto/tls"
+	"sync/atomic"
+
+	nexus "github.com/kercylan98/vivid-nexus"
+)
+
+var (
+	_ nexus.Session        = (*Session)(nil)
+	_ nexus.TLSInfoSession = (*Session)(nil)
+)
+
+// NewSession 基于已完成握手的 TLS 连接创建会话。
+func NewSession(sessionId string, conn *tls.Conn) *Session {
+	return &Session{
+		sessionId: sessionId,
+		conn:      conn,
+	}
+}
+
+type Session struct {
+	sessionId string
+	conn      *tls.Conn
+	closed    atomic.Bool
+}
+
+func (s *Session) GetSessionId() string {
+	return s.sessionId
+}
+
+func (s *Session) Read(p []byte) (n int, err error) {
+	return s.conn.Read(p)
+}
+
+func (s *Session) Write(p []byte) (n int, err error) {
+	return s.conn.Write(p)
+}
+
+func (s *Session) Close() error {
+	if !s.closed.CompareAndSwap(false, true) {
+		return nil
+	}
+	return s.conn.Close()
+}
+
+// ConnectionState 返回 TLS 握手信息，供业务通过 SessionContext.TLSConnectionState 读取对端证书与 SNI。
+func (s *Session) ConnectionState() tls.ConnectionState {
+	return s.conn.ConnectionState()
+}
@@ -0,0 +1,36 @@
+package nexus
+
+// FrameKind 描述一条出站消息的帧类型，如 WebSocket 的文本帧与二进制帧。
+type FrameKind uint8
+
+const (
+	// FrameKindDefault 表示不指定帧类型：使用会话通过 SetDefaultFrameKind 设置的默认值，未设置时由传输层的 Write 决定。
+	FrameKindDefault FrameKind = iota
+	// FrameKindText 表示文本帧。
+	FrameKindText
+	// FrameKindBinary 表示二进制帧。
+	FrameKindBinary
+)
+
+// String 返回帧类型的可读名称。
+func (k FrameKind) String() string {
+	switch k {
+	case FrameKindDefault:
+		return "default"
+	case FrameKindText:
+		return "text"
+	case FrameKindBinary:
+		return "binary"
+	default:
+		return "unknown"
+	}
+}
+
+// FrameKindWriter 是 Session 的可选扩展，由区分帧类型的传输层（如 WebSocket）实现。
+//
+// 写出帧类型不为 FrameKindDefault 的消息时，Nexus 调用 WriteFrame 代替 Write；未实现该接口的 Session 忽略帧类型，始终调用 Write。
+type FrameKindWriter interface {
+	Session
+	// WriteFrame 以 kind 指定的帧类型写出 p，返回值语义同 io.Writer.Write。
+	WriteFrame(kind FrameKind, p []byte) (n int, err error)
+}
@@ -0,0 +1,64 @@
+package nexus
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// Framer 将一条出站消息编码为线上字节，与入站分帧 SessionReader（如 NewHeaderBodyReader）对称，配合 WithOutboundFraming 使用。
+type Framer interface {
+	// AppendFrame 将 payload 编码后的完整帧追加到 dst 并返回结果，返回值语义同 append；dst 为可复用的缓冲区。
+	AppendFrame(dst, payload []byte) ([]byte, error)
+}
+
+// FramerFN 是 Framer 的函数式适配器类型。
+type FramerFN func(dst, payload []byte) ([]byte, error)
+
+// AppendFrame 调用函数并返回编码后的帧。
+func (fn FramerFN) AppendFrame(dst, payload []byte) ([]byte, error) {
+	return fn(dst, payload)
+}
+
+// NewLengthPrefixFramer 返回以 size 字节长度前缀（按 order 编码，仅包含消息体长度）分帧的 Framer，size 取值为 1、2、4 或 8。
+//
+// 消息体长度超出前缀可表示的范围时 AppendFrame 返回 ErrFrameTooLarge；size 非法时每次 AppendFrame 都返回错误。
+func NewLengthPrefixFramer(size int, order binary.AppendByteOrder) Framer {
+	return FramerFN(func(dst, payload []byte) ([]byte, error) {
+		length := uint64(len(payload))
+		switch size {
+		case 1:
+			if length > 0xff {
+				return dst, ErrFrameTooLarge
+			}
+			dst = append(dst, byte(length))
+		case 2:
+			if length > 0xffff {
+				return dst, ErrFrameTooLarge
+			}
+			dst = order.AppendUint16(dst, uint16(length))
+		case 4:
+			if length > 0xffffffff {
+				return dst, ErrFrameTooLarge
+			}
+			dst = order.AppendUint32(dst, uint32(length))
+		case 8:
+			dst = order.AppendUint64(dst, length)
+		default:
+			return dst, fmt.Errorf("length prefix framer: invalid prefix size %d", size)
+		}
+		return append(dst, payload...), nil
+	})
+}
+
+// NewDelimiterFramer 返回在每条消息末尾追加 delimiter 的 Framer，消息体中包含 delimiter 时 AppendFrame 返回错误。
+func NewDelimiterFramer(delimiter []byte) Framer {
+	delimiter = bytes.Clone(delimiter)
+	return FramerFN(func(dst, payload []byte) ([]byte, error) {
+		if len(delimiter) > 0 && bytes.Contains(payload, delimiter) {
+			return dst, fmt.Errorf("delimiter framer: payload contains delimiter %q", delimiter)
+		}
+		dst = append(dst, payload...)
+		return append(dst, delimiter...), nil
+	})
+}
@@ -5,6 +5,6 @@ go 1.26
 replace github.com/kercylan98/vivid => ../vivid
 
 require (
-	github.com/google/uuid v1.6.0 // indirect
+	github.com/google/uuid v1.6.0
 	github.com/kercylan98/vivid v0.1.4 // indirect
 )
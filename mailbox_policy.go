@@ -0,0 +1,60 @@
+package nexus
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/kercylan98/vivid"
+)
+
+// errMailboxFull 表示 MailboxFullPolicyClose 下上一条入站消息尚未处理完成时又读到了新消息。
+var errMailboxFull = errors.New("session mailbox full")
+
+// MailboxFullPolicy 描述读循环读到新的入站消息、而上一条入站消息仍在会话邮箱中等待或处理时如何处理。
+//
+// 会话邮箱中任一时刻至多存在一条由读循环投递的入站消息，该策略决定超出这一容量的消息的去向，使高负载下的行为不依赖 vivid 邮箱的实现。
+type MailboxFullPolicy uint8
+
+const (
+	// MailboxFullPolicyBlock 暂停读取，直到上一条消息处理完成后再投递新消息，数据滞留在传输层（背压），为默认策略。
+	MailboxFullPolicyBlock MailboxFullPolicy = iota
+	// MailboxFullPolicyDrop 丢弃新消息并调用 Options.MailboxFullHandler，读循环继续读取。
+	MailboxFullPolicyDrop
+	// MailboxFullPolicyClose 以 ReasonMailboxFull 关闭会话。
+	MailboxFullPolicyClose
+)
+
+// readOffering 是非 MailboxFullPolicyBlock 策略下的读循环：不等待上一条消息处理完成，读到新消息时若上一条仍未处理完成则按策略处理。
+//
+// 每次投递的消息在处理完成后恰好向 messageC 发送一次信号，inflight 记录是否仍有未取走信号的消息，
+// 因此邮箱中至多存在一条入站消息，release 向 messageC 的发送也不会阻塞邮箱线程。
+func (a *sessionActor) readOffering(ctx vivid.ActorContext) error {
+	var inflight bool
+	for {
+		data, err := a.readFrame()
+		if err != nil {
+			return err
+		}
+		if inflight {
+			select {
+			case <-a.messageC:
+				inflight = false
+			default:
+			}
+		}
+		if inflight {
+			if a.options.MailboxFullPolicy == MailboxFullPolicyClose {
+				return errMailboxFull
+			}
+			if handler := a.options.MailboxFullHandler; handler != nil {
+				handler(a.context.GetSessionId(), data)
+			}
+			continue
+		}
+
+		// 读循环继续读取时 Reader 可能复用缓冲区，需拷贝后投递
+		inflight = true
+		a.context.sessionInfo.handlerBusy.Store(true)
+		ctx.TellSelf(bytes.Clone(data))
+	}
+}
@@ -0,0 +1,98 @@
+package nexus_test
+
+import (
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	nexus "github.com/kercylan98/vivid-nexus"
+	"github.com/kercylan98/vivid-nexus/nexustest"
+)
+
+func TestMailboxFullPolicy(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		policy      nexus.MailboxFullPolicy
+		wantDropped []string
+		wantClosed  bool
+	}{
+		{name: "drop", policy: nexus.MailboxFullPolicyDrop, wantDropped: []string{"b"}},
+		{name: "close", policy: nexus.MailboxFullPolicyClose, wantClosed: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var lock sync.Mutex
+			var received, dropped []string
+			snapshot := func(s *[]string) []string {
+				lock.Lock()
+				defer lock.Unlock()
+				return slices.Clone(*s)
+			}
+			started, release := make(chan struct{}), make(chan struct{})
+			mailboxFull := make(chan struct{}, 1)
+			n := newTestNexus(t, provide(&testActor{message: func(ctx nexus.SessionContext, message []byte) {
+				lock.Lock()
+				received = append(received, string(message))
+				lock.Unlock()
+				if string(message) == "a" {
+					close(started)
+					<-release
+				}
+			}}), nexus.WithMailboxFullPolicy(tc.policy, func(sessionId string, message []byte) {
+				lock.Lock()
+				dropped = append(dropped, string(message))
+				lock.Unlock()
+			}), nexus.WithReasonFormatter(func(reason nexus.Reason, detail string) string {
+				if reason == nexus.ReasonMailboxFull {
+					mailboxFull <- struct{}{}
+				}
+				return string(reason)
+			}))
+
+			memory := nexustest.NewMemorySession("busy", nil)
+			takeover(t, n, memory)
+
+			// "a" 处理期间读到 "b"
+			if err := memory.Feed([]byte("a")); err != nil {
+				t.Fatalf("feed: %v", err)
+			}
+			<-started
+			if err := memory.Feed([]byte("b")); err != nil {
+				t.Fatalf("feed: %v", err)
+			}
+
+			// 读循环按策略处理 "b" 后再结束 "a" 的处理，会话关闭须等待 "a" 处理完成
+			if tc.wantClosed {
+				select {
+				case <-mailboxFull:
+				case <-time.After(testTimeout):
+					t.Fatal("timeout waiting for mailbox full")
+				}
+				close(release)
+				eventually(t, "session closed", memory.Closed)
+			} else {
+				eventually(t, "message dropped", func() bool { return len(snapshot(&dropped)) > 0 })
+				close(release)
+				if memory.Closed() {
+					t.Fatal("session closed with MailboxFullPolicyDrop")
+				}
+			}
+			if got := snapshot(&dropped); !slices.Equal(got, tc.wantDropped) {
+				t.Fatalf("dropped %q, want %q", got, tc.wantDropped)
+			}
+			if got := snapshot(&received); !slices.Equal(got, []string{"a"}) {
+				t.Fatalf("received %q, want [a]", got)
+			}
+		})
+	}
+}
+
+func TestMailboxFullPolicyValidate(t *testing.T) {
+	options := nexus.NewOptions(
+		nexus.WithMailboxFullPolicy(nexus.MailboxFullPolicyDrop, nil),
+		nexus.WithMessageBatching(8, 0),
+	)
+	if err := options.Validate(); err == nil {
+		t.Fatal("mailbox full policy with message batching passed validation")
+	}
+}
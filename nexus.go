@@ -1,24 +1,66 @@
 // Package nexus 提供基于 vivid 的会话管理层。
 package nexus
 
-import "github.com/kercylan98/vivid"
+import (
+	"context"
+	"io"
+	"time"
 
-// Nexus 是会话托管与消息分发的入口。
+	"github.com/kercylan98/vivid"
+)
+
+// Nexus 是会话托管与消息分发的入口，由 New 构造。
+//
+// 接口由 Inject（将 Nexus Actor 注册到 ActorSystem）与 Send、Broadcast、Close、TakeoverSession 等常用会话操作方法组成，
+// 不暴露内部实现，便于业务依赖接口并在测试中模拟。较少使用的操作按用途分组在 TakeoverNexus、CloseNexus、DeliveryNexus、
+// GroupNexus、LookupNexus 与 BroadcastNexus 等扩展接口中，New 返回的 Nexus 总是实现全部扩展接口，需要时通过类型断言获取；
+// 模拟实现只需实现 Nexus 及实际用到的扩展接口，后续新增的操作也优先加入扩展接口，而不是扩大 Nexus 本身。
+//
+// Nexus Actor 启动（处理 OnLaunch）前，TakeoverSession 与 Send、SendWait、Ask 等返回 error 的发送方法返回 ErrNotStarted，
+// Broadcast、Close 等无返回值的方法无操作；可通过 Started 或 WaitStarted 与启动同步。
+//
+// 在会话自身的回调（OnConnected、OnMessage 等，运行在该会话的邮箱线程）中调用 Nexus 方法时须遵守以下规则，否则会自我阻塞：
+//   - 可以安全调用：Send、SendTo、Broadcast 等发送方法直接持有会话的写锁写出（或入队），写锁与邮箱相互独立，不会等待邮箱；
+//     Close、ForceClose、SendAndClose 等关闭方法仅投递 Kill，关闭在当前回调返回后才执行；SendWait 与 CloseAfterFlush 只等待写出，不等待邮箱。
+//   - 不可针对本会话调用：CloseWait、Detach 与 Drain 等待本会话完成关闭，而关闭需要当前回调先返回；Ask 与 WaitAck 等待的入站回复
+//     需由本会话的邮箱处理，等待期间邮箱被占用，只能以超时结束。需要这类语义时，应在其他 goroutine 中调用，或改用 TellLater 等异步方式。
+//   - 同一会话的写出由写锁串行化，但写锁不可重入：自定义 Session 的 Write 实现中不得再向同一会话发送。
 type Nexus interface {
 	// Inject 在特定 ActorSystem 中注入并创建 Nexus Actor，而后返回对应的 ActorRef。
 	//
 	// 该函数在多次调用时会始终返回相同的 ActorRef，不会多次创建 Nexus Actor。即便是不同的 ActorSystem。
 	Inject(system vivid.ActorSystem, options ...vivid.ActorOption) (vivid.ActorRef, error)
 
+	// Started 报告 Nexus Actor 是否已启动。
+	Started() bool
+
+	// WaitStarted 阻塞直到 Nexus Actor 启动或 ctx 结束，ctx 结束时返回 ctx.Err()。
+	WaitStarted(ctx context.Context) error
+
 	// TakeoverSession 接管会话并开始管理其生命周期与读写。
-	TakeoverSession(session Session)
+	// Nexus Actor 尚未启动时返回 ErrNotStarted，session 不会被接管，由调用方决定关闭或重试。
+	TakeoverSession(session Session) error
+
+	// Options 返回 Nexus 构造时生效的 Options 的副本，修改返回值不会影响 Nexus。
+	Options() Options
 
-	// Close 关闭指定 sessionId 的会话，不存在则无操作。
+	// Close 优雅关闭指定 sessionId 的会话：调用 OnDisconnected 并写出剩余缓冲后关闭连接，不存在则无操作。
 	Close(sessionId string)
 
-	// Send 向指定 sessionId 的会话发送消息，会话不存在或已关闭则返回 nil。
+	// ForceClose 强制关闭指定 sessionId 的会话：跳过 OnDisconnected 与缓冲写出，直接关闭连接，适用于对端已失效的场景。
+	ForceClose(sessionId string)
+
+	// Done 返回 sessionId 对应会话终止时关闭的通道，会话不存在时返回已关闭的通道。
+	Done(sessionId string) <-chan struct{}
+
+	// Send 向指定 sessionId 的会话发送消息。
+	// Nexus Actor 尚未启动时返回 ErrNotStarted；message 为空或会话不存在（含已被移除）时返回 nil；
+	// 会话仍被托管但已进入关闭流程时返回 ErrSessionClosing。
 	Send(sessionId string, message []byte) error
 
+	// SendWait 向指定 sessionId 的会话发送消息，并阻塞直到消息实际写出或失败；会话不存在时返回 ErrSessionNotFound。
+	SendWait(sessionId string, message []byte) error
+
 	// SendTo 向 sessionIds 中的每个会话发送 message，重复 id 只发一次。
 	// errorHandler 在任一会话发送失败时调用，返回 true 则中止后续发送。
 	SendTo(sessionIds []string, message []byte, errorHandler ...SendErrorHandler)
@@ -26,4 +68,166 @@ type Nexus interface {
 	// Broadcast 向当前所有托管会话广播 message。
 	// errorHandler 在任一会话发送失败时调用，返回 true 则中止后续发送。
 	Broadcast(message []byte, errorHandler ...SendErrorHandler)
+
+	// Events 返回会话生命周期事件（Opened、Closed、Replaced、Error）通道；通道满时新事件会被丢弃，使用方应持续消费。
+	Events() <-chan SessionEvent
+
+	// Snapshot 返回当前所有托管会话的状态快照，用于调试与诊断。
+	Snapshot() []SessionSnapshot
+}
+
+// TakeoverNexus 是 Nexus 的扩展接口，提供携带接入属性、批量接管与分离会话等接管相关操作。
+//
+// New 返回的 Nexus 总是实现该接口，可通过类型断言获取：n.(nexus.TakeoverNexus)。
+type TakeoverNexus interface {
+	// TakeoverSessionWithAttrs 接管会话，并在 OnConnected 之前将 attrs 写入会话元数据，便于携带认证信息等接入时的上下文。
+	TakeoverSessionWithAttrs(session Session, attrs map[string]any) error
+
+	// TakeoverSessions 批量接管 sessions，整批会话在一次写锁内完成注册，适用于短时间内大量连接到达的场景；nil 会被忽略。
+	TakeoverSessions(sessions []Session) error
+
+	// Detach 将 sessionId 对应的会话移出托管并停止其 sessionActor，不关闭底层 Session 而是将其返回，便于迁移到其他 Nexus。
+	// 底层 Session 需实现 ReadDeadlineSession，否则返回 ErrDetachUnsupported。
+	Detach(sessionId string) (Session, error)
+}
+
+// CloseNexus 是 Nexus 的扩展接口，提供按条件、带关闭码、等待完成或排空等关闭会话的操作。
+//
+// New 返回的 Nexus 总是实现该接口，可通过类型断言获取：n.(nexus.CloseNexus)。
+type CloseNexus interface {
+	// CloseWhere 筛选 pred 返回 true 的会话并优雅关闭，返回关闭数量；pred 在不持有 Nexus 锁时执行，筛选期间被替换的会话不会被关闭。
+	CloseWhere(pred func(ctx SessionContext) bool, reason string) int
+
+	// CloseWithCode 以关闭码与原因优雅关闭会话，底层 Session 实现 CodedCloser 时据此关闭连接，否则等同于 Close。
+	CloseWithCode(sessionId string, code int, reason string)
+
+	// SendAndClose 写出最后一条 message 后关闭 sessionId 对应的会话，期间不会有其他写入插入到 message 之后。
+	SendAndClose(sessionId string, message []byte) error
+
+	// CloseWait 优雅关闭 sessionId 对应的会话并等待其完成关闭或 ctx 结束，会话不存在时直接返回 nil。
+	CloseWait(ctx context.Context, sessionId string) error
+
+	// CloseAfterFlush 等待会话出站队列中已有的消息写出（最多 timeout）后优雅关闭会话，超时仍会关闭并返回 ErrSendTimeout。
+	CloseAfterFlush(sessionId string, timeout time.Duration) error
+
+	// Drain 优雅关闭所有会话并最多等待 timeout，超时时尚未开始优雅关闭的会话被强制关闭，
+	// 返回关闭成功、强制关闭、仍在优雅关闭中与关闭错误的汇总。
+	Drain(timeout time.Duration) DrainReport
+}
+
+// DeliveryNexus 是 Nexus 的扩展接口，提供最新值、优先级、流式、确认与请求-响应等逐会话发送方式。
+//
+// New 返回的 Nexus 总是实现该接口，可通过类型断言获取：n.(nexus.DeliveryNexus)。
+type DeliveryNexus interface {
+	// SendLatest 以最新值语义发送 topic 主题下的 message，启用出站队列时覆盖同会话同主题尚未写出的消息。
+	SendLatest(sessionId string, topic string, message []byte) error
+
+	// SendWithPriority 以指定优先级向 sessionId 的会话发送消息；启用出站队列时 priority 越大越先写出，同优先级保持 FIFO。
+	SendWithPriority(sessionId string, message []byte, priority int) error
+
+	// SendStream 将 r 中的数据分块写入 sessionId 的会话直到 io.EOF，返回写出的字节数；会话不存在时返回 ErrSessionNotFound。
+	// 整个流期间持有该会话的写锁，其他写入不会插入流中；耗时受 Options.StreamTimeout 限制，超时返回 ErrSendTimeout。
+	SendStream(sessionId string, r io.Reader) (int64, error)
+
+	// SendWithAck 以 AckEncoder 为 message 编码会话内单调递增的序号后推送，返回该序号供 WaitAck 等待确认；未配置 WithAck 时返回 ErrAckNotConfigured。
+	SendWithAck(sessionId string, message []byte) (seq uint64, err error)
+
+	// WaitAck 等待 sessionId 对应会话对 seq 的确认，超时返回 ErrAckTimeout，会话关闭返回 ErrSessionClosed。
+	WaitAck(sessionId string, seq uint64, timeout time.Duration) error
+
+	// Ask 向指定 sessionId 的会话发送 message，并等待首条满足 match 的入站消息作为回复。
+	// 匹配到的回复不会再投递给 SessionActor.OnMessage；会话不存在、关闭或 ctx 结束时返回 error。
+	Ask(ctx context.Context, sessionId string, message []byte, match func(message []byte) bool) ([]byte, error)
+
+	// SendToAny 以轮询方式从 sessionIds 中选出一个存活的会话推送 message，返回被选中的会话 ID；没有可选会话时返回 ErrSessionNotFound。
+	SendToAny(sessionIds []string, message []byte) (picked string, err error)
+}
+
+// GroupNexus 是 Nexus 的扩展接口，提供按所有者、房间与标签分组发送和关闭会话的操作。
+//
+// New 返回的 Nexus 总是实现该接口，可通过类型断言获取：n.(nexus.GroupNexus)。
+type GroupNexus interface {
+	// SendToOwner 向通过 SessionContext.SetOwner 归属于 key 的所有会话发送 message。
+	// errorHandler 语义同 SendTo。
+	SendToOwner(key string, message []byte, errorHandler ...SendErrorHandler)
+
+	// CloseOwner 关闭归属于 key 的所有会话，不存在则无操作。
+	CloseOwner(key string)
+
+	// BroadcastRoom 向通过 SessionContext.JoinRoom 加入 room 的所有会话发送 message。
+	// errorHandler 语义同 SendTo。
+	BroadcastRoom(room string, message []byte, errorHandler ...SendErrorHandler)
+
+	// BroadcastRoomPattern 向名称匹配 pattern（path.Match 语法）的所有房间的成员发送 message，成员去重后只发送一次。
+	// errorHandler 语义同 SendTo。
+	BroadcastRoomPattern(pattern string, message []byte, errorHandler ...SendErrorHandler)
+
+	// RoomSize 返回 room 中当前的会话数量，房间不存在时返回 0。
+	RoomSize(room string) int
+
+	// Rooms 返回当前至少有一个会话的所有房间名称，顺序不固定。
+	Rooms() []string
+
+	// BroadcastTag 向通过 SessionContext.SetTags 带有 tag 的所有会话发送 message。
+	// errorHandler 语义同 SendTo。
+	BroadcastTag(tag string, message []byte, errorHandler ...SendErrorHandler)
+
+	// TagSize 返回带有 tag 的会话数量，标签不存在时返回 0。
+	TagSize(tag string) int
+
+	// Tags 返回当前至少被一个会话使用的所有标签，顺序不固定。
+	Tags() []string
+}
+
+// LookupNexus 是 Nexus 的扩展接口，提供按 ActorRef 或 SessionKey 查找会话、查询会话状态与遍历会话的操作。
+//
+// New 返回的 Nexus 总是实现该接口，可通过类型断言获取：n.(nexus.LookupNexus)。
+type LookupNexus interface {
+	// SessionIdByRef 返回会话 Actor 的 ref 对应的 sessionId，ref 不属于任何托管会话时返回 false。
+	SessionIdByRef(ref vivid.ActorRef) (string, bool)
+
+	// SessionIdByKey 返回 SessionKey（由 SessionKeyer 提供）为 key 的托管会话 ID，不存在时返回 false。
+	SessionIdByKey(key SessionKey) (string, bool)
+
+	// SendByKey 向 SessionKey 为 key 的会话推送 message，会话不存在时返回 ErrSessionNotFound。
+	SendByKey(key SessionKey, message []byte) error
+
+	// CloseByKey 优雅关闭 SessionKey 为 key 的会话，不存在时无操作。
+	CloseByKey(key SessionKey)
+
+	// IsManaged 返回 ref 是否为仍被托管的会话 Actor；Nexus 自身的 ref 返回 false。
+	IsManaged(ref vivid.ActorRef) bool
+
+	// PendingWrites 返回会话出站队列中尚未写出的消息数量，未启用出站队列时为 0；会话不存在时返回 ErrSessionNotFound。
+	PendingWrites(sessionId string) (int, error)
+
+	// HandlerBusy 报告会话是否有尚未处理完成的入站消息；会话不存在时返回 ErrSessionNotFound。
+	HandlerBusy(sessionId string) (bool, error)
+
+	// ForEachSession 依次以各托管会话的 SessionContext 调用 fn，fn 返回 false 时提前终止。
+	// fn 运行在调用方 goroutine 中，可安全调用 Send、Close 等方法，但不应使用内嵌 vivid.ActorContext 的方法。
+	ForEachSession(fn func(ctx SessionContext) (keepGoing bool))
+}
+
+// BroadcastNexus 是 Nexus 的扩展接口，提供共享缓冲区、可取消与带结果汇总的广播，以及暂停与恢复广播。
+//
+// New 返回的 Nexus 总是实现该接口，可通过类型断言获取：n.(nexus.BroadcastNexus)。
+type BroadcastNexus interface {
+	// BroadcastShared 向当前所有托管会话推送同一块只读的 message，对实现 SharedWriter 的会话不再逐个拷贝；调用后不得修改 message。
+	BroadcastShared(message []byte, errorHandler ...SendErrorHandler)
+
+	// BroadcastContext 向当前所有托管会话推送 message，ctx 结束时停止后续发送，返回成功写入的会话数量及 ctx.Err()。
+	BroadcastContext(ctx context.Context, message []byte) (sent int, err error)
+
+	// BroadcastResult 向当前所有托管会话广播 message，并返回发送结果汇总。
+	BroadcastResult(message []byte) BroadcastReport
+
+	// PauseBroadcast 暂停 Broadcast、SendTo 等所有扇出发送，暂停期间的广播按 WithBroadcastPauseQueue 暂存或丢弃；逐会话 Send 不受影响。
+	PauseBroadcast()
+
+	// ResumeBroadcast 恢复扇出发送并按顺序重放暂停期间暂存的广播，返回重放的数量。
+	ResumeBroadcast() (replayed int)
+
+	// IsBroadcastPaused 返回广播当前是否被 PauseBroadcast 暂停。
+	IsBroadcastPaused() bool
 }
@@ -0,0 +1,79 @@
+package nexustest
+
+import (
+	"sync"
+
+	nexus "github.com/kercylan98/vivid-nexus"
+)
+
+var _ nexus.Session = (*FaultSession)(nil)
+
+// NewFaultSession 包装 session，返回可按调用次数注入读写错误的 FaultSession。
+func NewFaultSession(session nexus.Session) *FaultSession {
+	return &FaultSession{
+		Session:     session,
+		readFaults:  make(map[int]error),
+		writeFaults: make(map[int]error),
+	}
+}
+
+// FaultSession 包装一个 Session，可编排在第 N 次 Read/Write 时返回指定错误（如 io.ErrUnexpectedEOF 或自定义错误），
+// 用于验证读失败 → Kill → OnDisconnected 等错误处理路径，以及业务 SessionActor 对传输故障的处理。
+//
+// 未注入错误的调用直接委托给被包装的 Session；注入的错误只生效一次，且不会调用被包装 Session 的对应方法。所有方法并发安全。
+type FaultSession struct {
+	nexus.Session
+
+	lock        sync.Mutex
+	reads       int           // 已发生的 Read 次数
+	writes      int           // 已发生的 Write 次数
+	readFaults  map[int]error // 第 N 次 Read（从 1 开始）返回的错误
+	writeFaults map[int]error // 第 N 次 Write（从 1 开始）返回的错误
+}
+
+// FailReadAt 使第 n 次（从 1 开始计数，包含已发生的调用）Read 返回 err，返回 s 以便链式调用。
+func (s *FaultSession) FailReadAt(n int, err error) *FaultSession {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.readFaults[n] = err
+	return s
+}
+
+// FailWriteAt 使第 n 次（从 1 开始计数，包含已发生的调用）Write 返回 err，返回 s 以便链式调用。
+func (s *FaultSession) FailWriteAt(n int, err error) *FaultSession {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.writeFaults[n] = err
+	return s
+}
+
+// Read 在命中注入的错误时返回 (0, err)，否则委托给被包装的 Session。
+func (s *FaultSession) Read(p []byte) (n int, err error) {
+	if err := s.fault(&s.reads, s.readFaults); err != nil {
+		return 0, err
+	}
+	return s.Session.Read(p)
+}
+
+// Write 在命中注入的错误时返回 (0, err)，否则委托给被包装的 Session。
+func (s *FaultSession) Write(p []byte) (n int, err error) {
+	if err := s.fault(&s.writes, s.writeFaults); err != nil {
+		return 0, err
+	}
+	return s.Session.Write(p)
+}
+
+// fault 递增 counter 并返回本次调用需要注入的错误，未注入时返回 nil。
+func (s *FaultSession) fault(counter *int, faults map[int]error) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	*counter++
+	err, ok := faults[*counter]
+	if ok {
+		delete(faults, *counter)
+	}
+	return err
+}
@@ -0,0 +1,163 @@
+// Package nexustest 提供用于测试 Nexus 与业务 SessionActor 的内存 Session 及故障注入工具。
+package nexustest
+
+import (
+	"bytes"
+	"io"
+	"maps"
+	"os"
+	"sync"
+	"time"
+
+	nexus "github.com/kercylan98/vivid-nexus"
+)
+
+var (
+	_ nexus.Session             = (*MemorySession)(nil)
+	_ nexus.MetadataSession     = (*MemorySession)(nil)
+	_ nexus.ReadDeadlineSession = (*MemorySession)(nil)
+)
+
+// NewMemorySession 创建 ID 为 sessionId 的内存 Session，metadata 会被拷贝，可为 nil。
+func NewMemorySession(sessionId string, metadata map[string]any) *MemorySession {
+	s := &MemorySession{
+		sessionId: sessionId,
+		metadata:  maps.Clone(metadata),
+	}
+	s.cond = sync.NewCond(&s.lock)
+	return s
+}
+
+// MemorySession 是完全基于内存的 Session，用于在不建立真实连接的情况下驱动 Nexus。
+//
+// 测试通过 Feed 模拟对端发送的数据、通过 CloseWrite 模拟对端半关闭（EOF），通过 Written 断言写出的数据。
+// Read 在没有数据时阻塞，直到 Feed、CloseWrite、Close 或读超时到期。所有方法并发安全。
+type MemorySession struct {
+	sessionId string
+	metadata  map[string]any
+
+	lock     sync.Mutex
+	cond     *sync.Cond
+	inbound  bytes.Buffer // 尚未被读取的入站数据
+	eof      bool         // 对端已半关闭，读完剩余数据后返回 io.EOF
+	closed   bool         // 已被 Close
+	deadline time.Time    // 读超时，零值表示不超时
+	timer    *time.Timer  // 读超时到期时唤醒阻塞的 Read
+	written  [][]byte     // 每次 Write 写出的数据拷贝
+}
+
+// GetSessionId 返回本会话的唯一标识。
+func (s *MemorySession) GetSessionId() string {
+	return s.sessionId
+}
+
+// Metadata 返回创建时传入的元数据。
+func (s *MemorySession) Metadata() map[string]any {
+	return s.metadata
+}
+
+// Feed 模拟对端发送 data，data 会被拷贝；会话已关闭或已半关闭时返回 io.ErrClosedPipe。
+func (s *MemorySession) Feed(data []byte) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.closed || s.eof {
+		return io.ErrClosedPipe
+	}
+	s.inbound.Write(data)
+	s.cond.Broadcast()
+	return nil
+}
+
+// CloseWrite 模拟对端半关闭：已 Feed 的数据读完后 Read 返回 io.EOF，写方向不受影响。
+func (s *MemorySession) CloseWrite() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.eof = true
+	s.cond.Broadcast()
+}
+
+// Read 读取 Feed 写入的数据，没有数据时阻塞；半关闭或已关闭时返回 io.EOF，读超时到期时返回 os.ErrDeadlineExceeded。
+func (s *MemorySession) Read(p []byte) (n int, err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for s.inbound.Len() == 0 {
+		switch {
+		case s.closed, s.eof:
+			return 0, io.EOF
+		case !s.deadline.IsZero() && !time.Now().Before(s.deadline):
+			return 0, os.ErrDeadlineExceeded
+		}
+		s.cond.Wait()
+	}
+	return s.inbound.Read(p)
+}
+
+// Write 记录 p 的拷贝，会话已关闭时返回 io.ErrClosedPipe。
+func (s *MemorySession) Write(p []byte) (n int, err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.closed {
+		return 0, io.ErrClosedPipe
+	}
+	s.written = append(s.written, bytes.Clone(p))
+	return len(p), nil
+}
+
+// SetReadDeadline 设置读超时，零值表示不超时；到期后阻塞中的 Read 返回 os.ErrDeadlineExceeded。
+func (s *MemorySession) SetReadDeadline(t time.Time) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.deadline = t
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	if !t.IsZero() {
+		s.timer = time.AfterFunc(time.Until(t), func() {
+			s.lock.Lock()
+			defer s.lock.Unlock()
+			s.cond.Broadcast()
+		})
+	}
+	s.cond.Broadcast()
+	return nil
+}
+
+// Close 关闭会话，阻塞中的 Read 返回 io.EOF，此后 Write 返回 io.ErrClosedPipe；可重复调用。
+func (s *MemorySession) Close() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.closed = true
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	s.cond.Broadcast()
+	return nil
+}
+
+// Closed 报告会话是否已被 Close。
+func (s *MemorySession) Closed() bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.closed
+}
+
+// Written 返回迄今为止每次 Write 写出的数据拷贝，按写出顺序排列。
+func (s *MemorySession) Written() [][]byte {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	written := make([][]byte, len(s.written))
+	for i, data := range s.written {
+		written[i] = bytes.Clone(data)
+	}
+	return written
+}
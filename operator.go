@@ -1,44 +1,419 @@
 package nexus
 
-import "github.com/kercylan98/vivid"
+import (
+	"context"
+	"errors"
+	"maps"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/kercylan98/vivid"
+)
 
 // SendErrorHandler 在 Broadcast/SendTo 中某会话发送失败时被调用。
 //
-// 参数：sessionId 为当前发送的会话 ID；sessionContext 在 Broadcast 中目前传 nil；err 为本次 Write 的错误。
+// 参数：sessionId 为当前发送的会话 ID；sessionContext 为该会话的 SessionContext，仅当会话在发送后已被移除时为 nil；
+// err 为本次 Write 的错误。handler 运行在调用方 goroutine，仅应使用 sessionContext 中并发安全的方法（如 GetSessionId、GetMetadata、Close）。
 // 返回值：abort 为 true 时停止向后续会话发送，为 false 时继续。
 type SendErrorHandler = func(sessionId string, sessionContext SessionContext, err error) (abort bool)
 
 type operator struct {
-	actor        *Actor
-	actorContext vivid.ActorContext
+	actor          *Actor
+	actorContext   vivid.ActorContext
+	launched       atomic.Bool    // actorContext 注入后置为 true，用于在启动前拒绝依赖 actorContext 的操作
+	startedC       chan struct{}  // 首次启动时关闭，供 WaitStarted 等待
+	startOnce      sync.Once      // 确保 startedC 只被关闭一次
+	anyCursor      atomic.Uint64  // SendToAny 的轮询游标
+	broadcastPause broadcastPause // PauseBroadcast 的暂停状态与暂存队列
+}
+
+// Options 返回 Nexus 构造时生效的 Options 的副本，用于诊断或在启动日志中确认配置。
+//
+// 修改返回值不会影响 Nexus；其中的 SessionReaderProvider 与各回调函数与 Nexus 共享同一实现，调用方不应修改其内部状态。
+func (o *operator) Options() Options {
+	return o.actor.options
 }
 
 // TakeoverSession 用于接管一个已存在的 Session，并存入 operator 的会话管理中。
 // 如果 sessionId 已存在，原有会话会被关闭并替换为新会话。
 //
+// 若 Nexus Actor 尚未启动则返回 ErrNotStarted，此时 session 不会被接管也不会被关闭，由调用方自行处理。
 // 也可以直接通过将 Session Tell 到 Nexus Actor 的 ref 来达到等效的作用。
-func (o *operator) TakeoverSession(session Session) {
+func (o *operator) TakeoverSession(session Session) error {
+	if session == nil {
+		return errors.New("session is nil")
+	}
+	if !o.launched.Load() {
+		return ErrNotStarted
+	}
 	o.actorContext.TellSelf(session)
+	return nil
+}
+
+// sessionBatch 是 TakeoverSessions 投递给 Nexus Actor 的一批待接管会话。
+type sessionBatch []Session
+
+// TakeoverSessions 批量接管 sessions，语义与逐个调用 TakeoverSession 相同，nil 会被忽略。
+//
+// 整批会话作为一条内部消息投递给 Nexus Actor，逐个完成准入检查后在一次 sessions 写锁内创建 sessionActor 并注册，
+// 适用于重连风暴等短时间内大量连接到达的场景，以减少对会话表的锁竞争。若 Nexus Actor 尚未启动则返回 ErrNotStarted，
+// 此时所有 session 都不会被接管也不会被关闭，由调用方自行处理。
+func (o *operator) TakeoverSessions(sessions []Session) error {
+	if !o.launched.Load() {
+		return ErrNotStarted
+	}
+	var batch = make(sessionBatch, 0, len(sessions))
+	for _, session := range sessions {
+		if session != nil {
+			batch = append(batch, session)
+		}
+	}
+	if len(batch) == 0 {
+		return nil
+	}
+	o.actorContext.TellSelf(batch)
+	return nil
 }
 
-// Close 关闭指定 ID 的会话。
+// attributedSession 是 TakeoverSessionWithAttrs 投递给 Nexus Actor 的待接管会话及其接入属性。
+type attributedSession struct {
+	session Session
+	attrs   map[string]any
+}
+
+// TakeoverSessionWithAttrs 接管 session，并在 OnConnected 之前将 attrs 写入该会话的元数据，其余语义同 TakeoverSession。
+//
+// 用于将接入时已掌握的上下文（如认证声明、查询参数）带入会话，无需为此扩展自定义 Session 的字段；
+// 在回调中通过 SessionContext.GetMetadata 读取。attrs 会被拷贝，与 MetadataSession 提供的元数据同名时以 attrs 为准。
+func (o *operator) TakeoverSessionWithAttrs(session Session, attrs map[string]any) error {
+	if session == nil {
+		return errors.New("session is nil")
+	}
+	if !o.launched.Load() {
+		return ErrNotStarted
+	}
+	o.actorContext.TellSelf(attributedSession{session: session, attrs: maps.Clone(attrs)})
+	return nil
+}
+
+// Close 优雅关闭指定 ID 的会话。
 //
 // 若该 sessionId 存在托管会话，则 Kill 对应 sessionActor（映射在 OnKilled 时移除，底层 Session 由 session 侧关闭）；
+// 关闭过程中会调用 OnDisconnected 并写出 BufferWrite 中剩余的数据，适用于连接仍然可用的常规关闭。
 // 若不存在则无操作，可安全重复调用。并发安全。
 func (o *operator) Close(sessionId string) {
 	o.actor.sessionLock.Lock()
 	defer o.actor.sessionLock.Unlock()
 
 	if session, ok := o.actor.sessions[sessionId]; ok {
-		o.actorContext.Kill(session.ref, false, "close session")
+		o.actorContext.Kill(session.ref, false, o.actor.options.formatReason(ReasonClose, ""))
+	}
+}
+
+// CloseWait 优雅关闭指定 ID 的会话，并阻塞直到 sessionActor 完成关闭（OnDisconnected 返回且底层 Session 已关闭）或 ctx 结束。
+//
+// 适用于后续操作依赖旧会话被完全拆除的场景，例如复用其 sessionId 前。会话不存在时视为已关闭，直接返回 nil；
+// ctx 结束时返回 ctx.Err()，此时关闭仍会在后台继续。不可在该会话自身的回调中调用，否则会因等待自身邮箱而阻塞到 ctx 结束。
+func (o *operator) CloseWait(ctx context.Context, sessionId string) error {
+	o.actor.sessionLock.RLock()
+	info, ok := o.actor.sessions[sessionId]
+	if ok {
+		o.actorContext.Kill(info.ref, false, o.actor.options.formatReason(ReasonClose, ""))
+	}
+	o.actor.sessionLock.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	select {
+	case <-info.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// CloseAfterFlush 等待指定 ID 会话的出站队列中已有的消息全部写出后再优雅关闭该会话，适用于登出等需要确保最后的状态更新送达客户端的场景。
+//
+// 最多等待 timeout，超时后仍会关闭会话并返回 ErrSendTimeout，此时尚未写出的消息在关闭底层 Session 前继续写出；timeout 小于等于 0 时不限制等待时间。
+// 等待期间新入队的消息若优先级不低于已有消息也会先被写出，但不保证在关闭前写出。未启用 WithSendQueue 时消息均为同步写出，等同于 Close。
+// 会话不存在时返回 ErrSessionNotFound；等待期间会话被关闭时返回 ErrSessionClosed。
+// 等待只依赖会话独立的写循环而不依赖邮箱，因此可以在该会话自身的回调中调用，但等待期间会占用邮箱。
+func (o *operator) CloseAfterFlush(sessionId string, timeout time.Duration) error {
+	o.actor.sessionLock.RLock()
+	info, ok := o.actor.sessions[sessionId]
+	o.actor.sessionLock.RUnlock()
+	if !ok {
+		return ErrSessionNotFound
+	}
+
+	var err error
+	if info.queue != nil {
+		err = info.awaitQueueDrained(timeout)
+	}
+	o.actor.sessionLock.RLock()
+	if o.actor.sessions[sessionId] == info {
+		o.actorContext.Kill(info.ref, false, o.actor.options.formatReason(ReasonClose, ""))
+	}
+	o.actor.sessionLock.RUnlock()
+	return err
+}
+
+// PendingWrites 返回指定会话出站队列中尚未写出的消息数量，会话不存在时返回 ErrSessionNotFound。
+//
+// 未启用 WithSendQueue 时写出为同步进行，总是返回 0。生产者可据此判断会话是否跟不上发送速度并自适应地降低发送速率；
+// 结果仅反映调用时刻的状态。
+func (o *operator) PendingWrites(sessionId string) (int, error) {
+	o.actor.sessionLock.RLock()
+	info, ok := o.actor.sessions[sessionId]
+	o.actor.sessionLock.RUnlock()
+	if !ok {
+		return 0, ErrSessionNotFound
+	}
+	return info.pendingWrites(), nil
+}
+
+// HandlerBusy 报告指定会话是否有已读取、但尚未被业务处理完成的入站消息，会话不存在时返回 ErrSessionNotFound。
+//
+// 读循环在上一条消息处理完成前不会读取下一条，因此持续为 true 表示会话的处理速度已成为瓶颈，数据正滞留在传输层。
+func (o *operator) HandlerBusy(sessionId string) (bool, error) {
+	o.actor.sessionLock.RLock()
+	info, ok := o.actor.sessions[sessionId]
+	o.actor.sessionLock.RUnlock()
+	if !ok {
+		return false, ErrSessionNotFound
+	}
+	return info.handlerBusy.Load(), nil
+}
+
+// killManaged 在 info 仍被托管（未被移除或替换）时以 reason 与 detail Kill 其 sessionActor。
+func (o *operator) killManaged(info *sessionInfo, reason Reason, detail string) {
+	o.actor.sessionLock.RLock()
+	defer o.actor.sessionLock.RUnlock()
+
+	if o.actor.sessions[info.GetSessionId()] == info {
+		o.actorContext.Kill(info.ref, false, o.actor.options.formatReason(reason, detail))
+	}
+}
+
+// CloseWhere 筛选 pred 返回 true 的会话并优雅关闭，返回关闭的会话数量，reason 作为关闭原因的细节记录。
+//
+// 适用于封禁用户等定向批量断开的场景。pred 在不持有 Nexus 锁时于调用方 goroutine 中对调用时刻的会话快照逐一执行，
+// 可以读取 GetSessionId、GetMetadata、HasTag 等会话状态，但不应阻塞；关闭时会在写锁内确认会话仍是筛选时的同一实例，
+// 筛选期间被同 ID 新会话替换的会话不会被关闭，因此不会误关新接管的会话。pred 为 nil 时返回 0。
+func (o *operator) CloseWhere(pred func(ctx SessionContext) bool, reason string) int {
+	if pred == nil {
+		return 0
+	}
+
+	o.actor.sessionLock.RLock()
+	infos := make([]*sessionInfo, 0, len(o.actor.sessions))
+	for _, info := range o.actor.sessions {
+		if info.context != nil {
+			infos = append(infos, info)
+		}
+	}
+	o.actor.sessionLock.RUnlock()
+
+	matched := infos[:0]
+	for _, info := range infos {
+		if pred(info.context) {
+			matched = append(matched, info)
+		}
+	}
+	if len(matched) == 0 {
+		return 0
+	}
+
+	o.actor.sessionLock.Lock()
+	defer o.actor.sessionLock.Unlock()
+
+	var count int
+	for _, info := range matched {
+		if o.actor.sessions[info.GetSessionId()] != info {
+			continue
+		}
+		o.actorContext.Kill(info.ref, false, o.actor.options.formatReason(ReasonClose, reason))
+		count++
+	}
+	return count
+}
+
+// CloseWithCode 以关闭码 code 与原因 reason 优雅关闭指定 ID 的会话，其余语义同 Close。
+//
+// 底层 Session 实现了 CodedCloser 时，以 CloseWithCode(code, reason) 关闭连接（如发送指定关闭码的 WebSocket 关闭帧），
+// 否则退化为 Close；同一会话多次请求时仅首次的关闭码生效。reason 同时作为关闭原因的细节记录。不存在时无操作，并发安全。
+func (o *operator) CloseWithCode(sessionId string, code int, reason string) {
+	o.actor.sessionLock.Lock()
+	defer o.actor.sessionLock.Unlock()
+
+	if session, ok := o.actor.sessions[sessionId]; ok {
+		session.closeFrame.CompareAndSwap(nil, &closeFrame{code: code, reason: reason})
+		o.actorContext.Kill(session.ref, false, o.actor.options.formatReason(ReasonClose, reason))
+	}
+}
+
+// Done 返回在指定 ID 的会话终止（完成关闭或 sessionActor 被移除）时关闭的通道，会话不存在时返回已关闭的通道。
+//
+// 便于在 select 中同时等待多个会话结束，而无需轮询。返回的通道属于调用时刻托管的会话实例：
+// 此后同 ID 的新会话接管时，通道随旧会话的终止而关闭，不会跟随新会话。
+func (o *operator) Done(sessionId string) <-chan struct{} {
+	o.actor.sessionLock.RLock()
+	info, ok := o.actor.sessions[sessionId]
+	o.actor.sessionLock.RUnlock()
+	if !ok {
+		return closedC
+	}
+	return info.done
+}
+
+// closedC 是已关闭的通道，Done 在会话不存在时返回它。
+var closedC = func() chan struct{} {
+	c := make(chan struct{})
+	close(c)
+	return c
+}()
+
+// ForceClose 强制关闭指定 ID 的会话。
+//
+// 与 Close 不同，关闭时不调用 OnDisconnected、不写出剩余缓冲，也不等待 writeLock，直接关闭底层 Session；
+// 适用于已知对端失效（如心跳超时、写入持续失败）的场景，避免向死连接做无意义的告别与回调。
+// 会话已开始优雅关闭（如正在执行 OnDisconnected）时不再改变其关闭方式。若不存在则无操作，可安全重复调用。并发安全。
+func (o *operator) ForceClose(sessionId string) {
+	o.actor.sessionLock.Lock()
+	defer o.actor.sessionLock.Unlock()
+
+	if session, ok := o.actor.sessions[sessionId]; ok {
+		session.markForceClose()
+		o.actorContext.Kill(session.ref, false, o.actor.options.formatReason(ReasonForceClose, ""))
 	}
 }
 
 // Send 向指定 ID 的会话推送消息（写回底层 Session）。
 //
-// 若 message 为空则直接返回 nil；若 sessionId 不存在或已关闭则返回 nil（不返回错误）。
+// Nexus Actor 尚未启动时返回 ErrNotStarted；若 message 为空则直接返回 nil；若 sessionId 不存在或已被移除则返回 nil（不返回错误）；
+// 会话仍被托管但已进入关闭流程（如对端 EOF 触发关闭、剩余缓冲已写出）时返回 ErrSessionClosing。
 // 同一会话的多次 Send 由 session 侧 writeLock 串行化，并发安全。
 func (o *operator) Send(sessionId string, message []byte) error {
+	if !o.launched.Load() {
+		return ErrNotStarted
+	}
+	_, err := o.send(sessionId, message, false)
+	return err
+}
+
+// send 是 Send 的内部实现，readyOnly 为 true 时会跳过尚未就绪的会话。
+// attempted 报告是否实际向会话发起了写入，会话不存在或被跳过时为 false。
+func (o *operator) send(sessionId string, message []byte, readyOnly bool) (attempted bool, err error) {
+	return o.deliver(sessionId, message, readyOnly, false, 0)
+}
+
+// deliver 与 send 相同，shared 为 true 时以共享缓冲区的方式发送，调用方保证 message 在写出前不被修改；
+// timeout 大于 0 时同步写出最多等待 timeout，语义见 Options.BroadcastSendTimeout。
+func (o *operator) deliver(sessionId string, message []byte, readyOnly, shared bool, timeout time.Duration) (attempted bool, err error) {
+	if len(message) == 0 {
+		return false, nil
+	}
+
+	o.actor.sessionLock.RLock()
+	defer o.actor.sessionLock.RUnlock()
+
+	if info, ok := o.actor.sessions[sessionId]; ok {
+		if readyOnly && !info.ready.Load() {
+			return false, nil
+		}
+		if timeout > 0 {
+			return true, info.sendTimeout(message, shared, timeout)
+		}
+		if shared {
+			return true, info.sendShared(message)
+		}
+		return true, info.send(message)
+	}
+	return false, nil
+}
+
+// SendWait 向指定 ID 的会话推送消息，并阻塞直到消息实际写入底层 Session 或写入失败。
+//
+// 与 Send 不同，会话不存在时返回 ErrSessionNotFound，便于调用方确认投递结果；message 为空时直接返回 nil。
+// 启用 WithSendQueue 时消息同样经由出站队列写出，等待期间会话关闭则返回 ErrSessionClosed。
+func (o *operator) SendWait(sessionId string, message []byte) error {
+	if !o.launched.Load() {
+		return ErrNotStarted
+	}
+	if len(message) == 0 {
+		return nil
+	}
+
+	o.actor.sessionLock.RLock()
+	info, ok := o.actor.sessions[sessionId]
+	o.actor.sessionLock.RUnlock()
+	if !ok {
+		return ErrSessionNotFound
+	}
+	return info.sendWait(message)
+}
+
+// SendAndClose 向指定 ID 的会话写出最后一条 message 后关闭该会话，适用于一次性响应后断开的场景。
+//
+// 写出与进入关闭流程在同一次 writeLock 持有期间完成：先写出 BufferWrite 中剩余的数据与 message，再将会话标记为关闭中，
+// 因此并发的 Send、Broadcast 不会插入到 message 之后，此后对该会话的写入均返回 ErrSessionClosing。
+// message 不经过出站队列，队列中尚未写出的消息会被丢弃。会话不存在时返回 ErrSessionNotFound；写出失败时仍会关闭会话并返回该错误。
+func (o *operator) SendAndClose(sessionId string, message []byte) error {
+	if !o.launched.Load() {
+		return ErrNotStarted
+	}
+	o.actor.sessionLock.RLock()
+	info, ok := o.actor.sessions[sessionId]
+	o.actor.sessionLock.RUnlock()
+	if !ok {
+		return ErrSessionNotFound
+	}
+
+	info.writeLock.Lock()
+	err := info.flush()
+	if err == nil && len(message) > 0 {
+		_, err = info.writeMessage(FrameKindDefault, message, false)
+	}
+	if !info.closing.Load() {
+		info.closing.Store(true)
+		o.actorContext.Kill(info.ref, false, o.actor.options.formatReason(ReasonSendAndClose, ""))
+	}
+	info.writeLock.Unlock()
+	return err
+}
+
+// SendWithPriority 以指定优先级向 ID 对应的会话推送消息，其余语义同 Send。
+//
+// 启用 WithSendQueue 时，priority 越大越先写出：高优先级消息会越过已排队的低优先级消息，同优先级保持 FIFO；
+// 未启用出站队列时消息同步写出，priority 不产生影响。
+func (o *operator) SendWithPriority(sessionId string, message []byte, priority int) error {
+	if !o.launched.Load() {
+		return ErrNotStarted
+	}
+	if len(message) == 0 {
+		return nil
+	}
+
+	o.actor.sessionLock.RLock()
+	defer o.actor.sessionLock.RUnlock()
+
+	if info, ok := o.actor.sessions[sessionId]; ok {
+		return info.sendWithPriority(message, priority)
+	}
+	return nil
+}
+
+// SendLatest 以“最新值”语义向指定 ID 的会话推送 topic 主题下的 message，适用于光标位置、遥测指标等只关心最新状态的高频同步。
+//
+// 启用 WithSendQueue 时，同一会话同一 topic 尚未写出的消息会被 message 覆盖（保留原排队位置，不额外占用队列容量），
+// 写出时只发送最新值；已开始写出的消息不受影响。未启用出站队列时消息同步写出，不发生合并。topic 为空时等同于 Send。
+// 其余语义同 Send：Nexus Actor 尚未启动时返回 ErrNotStarted，message 为空或会话不存在时返回 nil。
+func (o *operator) SendLatest(sessionId string, topic string, message []byte) error {
+	if !o.launched.Load() {
+		return ErrNotStarted
+	}
 	if len(message) == 0 {
 		return nil
 	}
@@ -47,37 +422,241 @@ func (o *operator) Send(sessionId string, message []byte) error {
 	defer o.actor.sessionLock.RUnlock()
 
 	if info, ok := o.actor.sessions[sessionId]; ok {
-		info.writeLock.Lock()
-		defer info.writeLock.Unlock()
-		_, err := info.Session.Write(message)
-		return err
+		return info.sendLatest(topic, message)
 	}
 	return nil
 }
 
+// Ask 向指定 ID 的会话推送 message，并阻塞等待首条满足 match 的入站消息作为回复。
+//
+// 匹配到的回复会被 Ask 消费，不再投递给 SessionActor.OnMessage；返回的回复为拷贝，可长期持有。
+// 会话不存在时返回 ErrSessionNotFound，等待期间会话关闭时返回 ErrSessionClosed，ctx 结束时返回 ctx.Err()。
+// 回复由目标会话的邮箱线程匹配，因此不可在该会话自身的回调中等待它的回复，否则只能等到 ctx 结束。
+func (o *operator) Ask(ctx context.Context, sessionId string, message []byte, match func(message []byte) bool) ([]byte, error) {
+	if !o.launched.Load() {
+		return nil, ErrNotStarted
+	}
+	if match == nil {
+		return nil, errors.New("ask match function is nil")
+	}
+
+	o.actor.sessionLock.RLock()
+	info, ok := o.actor.sessions[sessionId]
+	o.actor.sessionLock.RUnlock()
+	if !ok {
+		return nil, ErrSessionNotFound
+	}
+
+	waiter := newAskWaiter(match)
+	if !info.addWaiter(waiter) {
+		return nil, ErrSessionClosed
+	}
+	defer info.removeWaiter(waiter)
+
+	if err := info.send(message); err != nil {
+		return nil, err
+	}
+
+	select {
+	case reply, ok := <-waiter.reply:
+		if !ok {
+			return nil, ErrSessionClosed
+		}
+		return reply, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
 // SendTo 向 sessionIds 中的每个会话推送 message，对重复的 sessionId 只发送一次。
 //
 // 若 sessionIds 或 message 为空则直接返回。若提供了 errorHandler，则任一会话发送失败时调用
-// handler(sessionId, nil, err)；若某次 handler 返回 true 则中止后续发送。
+// handler(sessionId, sessionContext, err)；若某次 handler 返回 true 则中止后续发送。
+// 启用 WithBroadcastReadyOnly 时会跳过尚未就绪的会话。
 func (o *operator) SendTo(sessionIds []string, message []byte, errorHandler ...SendErrorHandler) {
+	o.sendTo(sessionIds, message, errorHandler)
+}
+
+// sendTo 是 SendTo 的内部实现，返回实际发起写入的会话数量。
+func (o *operator) sendTo(sessionIds []string, message []byte, errorHandler []SendErrorHandler) (attempted int) {
+	return o.fanOut(sessionIds, message, errorHandler, false)
+}
+
+// fanOut 依次向 sessionIds 中的会话发送 message 并对重复 ID 去重，shared 语义见 deliver，返回实际发起写入的会话数量。
+func (o *operator) fanOut(sessionIds []string, message []byte, errorHandler []SendErrorHandler, shared bool) (attempted int) {
 	if len(sessionIds) == 0 || len(message) == 0 {
 		return
 	}
+	if o.holdBroadcast(sessionIds, message, errorHandler, shared) {
+		return
+	}
 
-	var err error
-	var sended = make(map[string]struct{})
+	var sended = make(map[string]struct{}, len(sessionIds))
+	var targets = make([]string, 0, len(sessionIds))
 	for _, sessionId := range sessionIds {
 		if _, ok := sended[sessionId]; ok {
 			continue
 		}
 		sended[sessionId] = struct{}{}
-		err = o.Send(sessionId, message)
-		if err != nil && len(errorHandler) > 0 {
-			for _, handler := range errorHandler {
-				if abort := handler(sessionId, nil, err); abort {
+		targets = append(targets, sessionId)
+	}
+	if workers := o.actor.options.BroadcastConcurrency; workers > 1 && len(targets) > 1 {
+		return o.fanOutParallel(targets, message, errorHandler, shared, workers)
+	}
+
+	for _, sessionId := range targets {
+		sent, err := o.deliver(sessionId, message, o.actor.options.BroadcastReadyOnly, shared, o.actor.options.BroadcastSendTimeout)
+		if sent {
+			attempted++
+		}
+		if err != nil && o.reportSendError(sessionId, err, errorHandler) {
+			return
+		}
+	}
+	return
+}
+
+// fanOutParallel 由 workers 个 goroutine 并行向已去重的 sessionIds 发送 message，返回实际发起写入的会话数量。
+//
+// 每个会话的写入仍由各自的 writeLock 串行化；errorHandler 的调用被串行化，任一 handler 中止后各 goroutine 不再领取新的会话。
+func (o *operator) fanOutParallel(sessionIds []string, message []byte, errorHandler []SendErrorHandler, shared bool, workers int) (attempted int) {
+	var (
+		next        atomic.Int64
+		count       atomic.Int64
+		aborted     atomic.Bool
+		handlerLock sync.Mutex
+		wg          sync.WaitGroup
+	)
+	for range min(workers, len(sessionIds)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for !aborted.Load() {
+				i := next.Add(1) - 1
+				if i >= int64(len(sessionIds)) {
 					return
 				}
+				sessionId := sessionIds[i]
+				sent, err := o.deliver(sessionId, message, o.actor.options.BroadcastReadyOnly, shared, o.actor.options.BroadcastSendTimeout)
+				if sent {
+					count.Add(1)
+				}
+				if err != nil && len(errorHandler) > 0 {
+					handlerLock.Lock()
+					if !aborted.Load() && o.reportSendError(sessionId, err, errorHandler) {
+						aborted.Store(true)
+					}
+					handlerLock.Unlock()
+				}
 			}
+		}()
+	}
+	wg.Wait()
+	return int(count.Load())
+}
+
+// reportSendError 依次将 sessionId 的发送错误交给 errorHandler，任一 handler 返回 true 时停止调用并返回 true。
+func (o *operator) reportSendError(sessionId string, err error, errorHandler []SendErrorHandler) (abort bool) {
+	if len(errorHandler) == 0 {
+		return false
+	}
+	sessionContext := o.sessionContext(sessionId)
+	for _, handler := range errorHandler {
+		if handler(sessionId, sessionContext, err) {
+			return true
+		}
+	}
+	return false
+}
+
+// sessionContext 返回 sessionId 对应托管会话的 SessionContext，会话不存在时返回 nil。
+func (o *operator) sessionContext(sessionId string) SessionContext {
+	o.actor.sessionLock.RLock()
+	defer o.actor.sessionLock.RUnlock()
+
+	if info, ok := o.actor.sessions[sessionId]; ok && info.context != nil {
+		return info.context
+	}
+	return nil
+}
+
+// SendToAny 以轮询方式从 sessionIds 中选出一个存活的会话并推送 message，返回被选中的会话 ID。
+//
+// 仅在仍被托管且已就绪（OnConnected 完成）的会话中选择，多次调用时依次轮转，可将一组会话作为简单的负载均衡目标。
+// 没有可选会话时返回 ErrSessionNotFound；写入失败时返回被选中的会话 ID 与该错误，不会改投其他会话。
+func (o *operator) SendToAny(sessionIds []string, message []byte) (picked string, err error) {
+	if !o.launched.Load() {
+		return "", ErrNotStarted
+	}
+	o.actor.sessionLock.RLock()
+	var candidates = make([]*sessionInfo, 0, len(sessionIds))
+	for _, sessionId := range sessionIds {
+		if info, ok := o.actor.sessions[sessionId]; ok && info.ready.Load() {
+			candidates = append(candidates, info)
+		}
+	}
+	o.actor.sessionLock.RUnlock()
+	if len(candidates) == 0 {
+		return "", ErrSessionNotFound
+	}
+
+	info := candidates[(o.anyCursor.Add(1)-1)%uint64(len(candidates))]
+	picked = info.GetSessionId()
+	if len(message) == 0 {
+		return picked, nil
+	}
+	return picked, info.send(message)
+}
+
+// SessionIdByRef 返回 ref 对应的托管会话 ID，ref 不属于任何托管会话时返回 false。
+//
+// 用于其他 Actor 在收到会话 Actor 的 OnKilled 等消息时反查其所属会话。比较方式与 Nexus 内部一致，使用 ActorRef.Equals。
+func (o *operator) SessionIdByRef(ref vivid.ActorRef) (string, bool) {
+	if ref == nil {
+		return "", false
+	}
+
+	o.actor.sessionLock.RLock()
+	defer o.actor.sessionLock.RUnlock()
+
+	return o.actor.sessionIdByRef(ref)
+}
+
+// IsManaged 返回 ref 是否为仍被托管的会话 Actor，持有会话 ref 的其他 Actor 可在投递前据此避免向已终止的会话发送消息。
+//
+// 通过 ref 索引查找，开销与 SessionIdByRef 相同；Nexus 自身的 ref 与 nil 均返回 false。
+// 结果仅反映调用时刻的状态，返回 true 后会话仍可能随即关闭。
+func (o *operator) IsManaged(ref vivid.ActorRef) bool {
+	if ref == nil {
+		return false
+	}
+	if o.launched.Load() && ref.Equals(o.actorContext.Ref()) {
+		return false
+	}
+	_, ok := o.SessionIdByRef(ref)
+	return ok
+}
+
+// ForEachSession 依次以各托管会话的 SessionContext 调用 fn，fn 返回 false 时提前终止，遍历顺序不固定。
+//
+// 遍历基于调用时刻的会话列表拷贝进行，不持有 Nexus 的锁，因此 fn 中可以安全调用 Send、Close 等 operator 方法；
+// 遍历期间关闭的会话仍可能被访问到，新接管的会话则不会。fn 运行在调用方 goroutine 而非会话的邮箱线程，
+// 仅应使用 GetSessionId、Send、Close、GetMetadata 等并发安全的方法，不应调用内嵌 vivid.ActorContext 的方法。
+func (o *operator) ForEachSession(fn func(ctx SessionContext) (keepGoing bool)) {
+	if fn == nil {
+		return
+	}
+
+	o.actor.sessionLock.RLock()
+	contexts := make([]*sessionContext, 0, len(o.actor.sessions))
+	for _, info := range o.actor.sessions {
+		contexts = append(contexts, info.context)
+	}
+	o.actor.sessionLock.RUnlock()
+
+	for _, ctx := range contexts {
+		if !fn(ctx) {
+			return
 		}
 	}
 }
@@ -87,11 +666,53 @@ func (o *operator) SendTo(sessionIds []string, message []byte, errorHandler ...S
 // 先复制当前 sessions 的 key 列表再逐条 Send，避免持锁过久。若提供 errorHandler，
 // 则任一会话发送失败时调用 handler；若某次 handler 返回 true 则中止后续发送。
 func (o *operator) Broadcast(message []byte, errorHandler ...SendErrorHandler) {
-	var sessionIds = make([]string, 0, len(o.actor.sessions))
+	o.sendTo(o.sessionIds(), message, errorHandler)
+}
+
+// BroadcastShared 向当前所有托管会话推送同一块只读的 message 缓冲区，其余语义同 Broadcast。
+//
+// 对实现了 SharedWriter 的会话，Nexus 不再为每个会话拷贝 message（包括启用出站队列时的入队拷贝），而是将同一缓冲区交给各自的
+// WriteShared，适用于向大量会话广播同一负载的场景；未实现 SharedWriter 的会话按 Broadcast 的方式发送。
+// 调用方必须保证 message 在调用后不再被修改：启用出站队列时缓冲区会在 BroadcastShared 返回后才被写出。
+func (o *operator) BroadcastShared(message []byte, errorHandler ...SendErrorHandler) {
+	o.fanOut(o.sessionIds(), message, errorHandler, true)
+}
+
+// BroadcastContext 向当前所有托管会话推送 message，并在每次发送前检查 ctx，ctx 结束时停止后续发送。
+//
+// 返回成功写入的会话数量；因 ctx 结束而中止时同时返回 ctx.Err()，单个会话的写入失败不会中止广播。
+// 适用于关闭流程或请求超时等需要限制扇出耗时的场景，其余语义同 Broadcast。
+// 广播被 PauseBroadcast 暂停时不发送并返回 ErrBroadcastPaused，即使 message 已被暂存待重放。
+func (o *operator) BroadcastContext(ctx context.Context, message []byte) (sent int, err error) {
+	if !o.launched.Load() {
+		return 0, ErrNotStarted
+	}
+	if len(message) == 0 {
+		return 0, nil
+	}
+	sessionIds := o.sessionIds()
+	if o.holdBroadcast(sessionIds, message, nil, false) {
+		return 0, ErrBroadcastPaused
+	}
+	for _, sessionId := range sessionIds {
+		if err = ctx.Err(); err != nil {
+			return sent, err
+		}
+		if attempted, err := o.deliver(sessionId, message, o.actor.options.BroadcastReadyOnly, false, o.actor.options.BroadcastSendTimeout); attempted && err == nil {
+			sent++
+		}
+	}
+	return sent, nil
+}
+
+// sessionIds 返回当前所有托管会话 ID 的拷贝。
+func (o *operator) sessionIds() []string {
 	o.actor.sessionLock.RLock()
+	defer o.actor.sessionLock.RUnlock()
+
+	var sessionIds = make([]string, 0, len(o.actor.sessions))
 	for sessionId := range o.actor.sessions {
 		sessionIds = append(sessionIds, sessionId)
 	}
-	o.actor.sessionLock.RUnlock()
-	o.SendTo(sessionIds, message, errorHandler...)
+	return sessionIds
 }
@@ -0,0 +1,142 @@
+package nexus_test
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kercylan98/vivid/pkg/log"
+
+	nexus "github.com/kercylan98/vivid-nexus"
+	"github.com/kercylan98/vivid-nexus/nexustest"
+)
+
+func TestCloseWhereHasTag(t *testing.T) {
+	actor := &testActor{connected: func(ctx nexus.SessionContext) {
+		if tag, ok := ctx.GetMetadata("tag").(string); ok {
+			ctx.SetTags(tag)
+		}
+	}}
+	n := newTestNexus(t, provide(actor))
+
+	sessions := map[string]*nexustest.MemorySession{
+		"banned-1": nexustest.NewMemorySession("banned-1", map[string]any{"tag": "banned"}),
+		"banned-2": nexustest.NewMemorySession("banned-2", map[string]any{"tag": "banned"}),
+		"normal":   nexustest.NewMemorySession("normal", map[string]any{"tag": "normal"}),
+		"untagged": nexustest.NewMemorySession("untagged", nil),
+	}
+	for _, session := range sessions {
+		takeover(t, n, session)
+	}
+
+	// pred 中调用会获取 Nexus 读锁的 HasTag，不得死锁
+	closed := n.(nexus.CloseNexus).CloseWhere(func(ctx nexus.SessionContext) bool {
+		return ctx.HasTag("banned")
+	}, "banned")
+	if closed != 2 {
+		t.Fatalf("CloseWhere closed %d sessions, want 2", closed)
+	}
+
+	for id, session := range sessions {
+		if wantClosed := id == "banned-1" || id == "banned-2"; wantClosed {
+			eventually(t, id+" closed", session.Closed)
+		} else if session.Closed() {
+			t.Errorf("session %s closed, want open", id)
+		}
+	}
+}
+
+// latencySession 模拟写出耗时为 latency 的传输层，写出的数据被丢弃。
+type latencySession struct {
+	*nexustest.MemorySession
+	latency time.Duration
+}
+
+func (s *latencySession) Write(p []byte) (int, error) {
+	if s.latency > 0 {
+		time.Sleep(s.latency)
+	}
+	return len(p), nil
+}
+
+// BenchmarkBroadcastConcurrency 比较顺序广播与 WithBroadcastConcurrency 并行广播在不同写出耗时下的开销。
+func BenchmarkBroadcastConcurrency(b *testing.B) {
+	const sessions = 64
+	message := []byte("benchmark broadcast message")
+	for _, latency := range []time.Duration{0, 20 * time.Microsecond} {
+		for _, workers := range []int{0, 4, 16} {
+			b.Run(fmt.Sprintf("latency=%s/workers=%d", latency, workers), func(b *testing.B) {
+				n := newTestNexus(b, provide(&testActor{}), nexus.WithBroadcastConcurrency(workers))
+				for i := range sessions {
+					takeover(b, n, &latencySession{
+						MemorySession: nexustest.NewMemorySession("session-"+strconv.Itoa(i), nil),
+						latency:       latency,
+					})
+				}
+
+				b.ReportAllocs()
+				b.ResetTimer()
+				for b.Loop() {
+					n.Broadcast(message)
+				}
+			})
+		}
+	}
+}
+
+// TestUncomparableActorRef 验证 ActorRef 的具体类型不可比较时，会话的接管、关闭与 OnKilled 清理均正常工作。
+func TestUncomparableActorRef(t *testing.T) {
+	system := &testSystem{logger: log.NewTextLogger(), valueRefs: true}
+	n := newTestNexusOn(t, system, provide(&testActor{}))
+
+	memory := nexustest.NewMemorySession("value-ref", nil)
+	takeover(t, n, memory)
+	n.Close("value-ref")
+	eventually(t, "session closed", memory.Closed)
+	eventually(t, "session unregistered", func() bool { return len(n.Snapshot()) == 0 })
+}
+
+// blockingSession 的首次 Write 关闭 entered 后阻塞到 gate 关闭，后续 Write 直接阻塞到 gate 关闭。
+type blockingSession struct {
+	*nexustest.MemorySession
+	once    sync.Once
+	entered chan struct{}
+	gate    chan struct{}
+}
+
+func (s *blockingSession) Write(p []byte) (int, error) {
+	s.once.Do(func() { close(s.entered) })
+	<-s.gate
+	return s.MemorySession.Write(p)
+}
+
+// TestBroadcastSendTimeoutOnLockedSession 验证会话的 writeLock 被长时间占用时，广播在 BroadcastSendTimeout 后放弃该会话并报告 ErrSendTimeout。
+func TestBroadcastSendTimeoutOnLockedSession(t *testing.T) {
+	const timeout = 50 * time.Millisecond
+	n := newTestNexus(t, provide(&testActor{}), nexus.WithBroadcastSendTimeout(timeout))
+
+	slow := &blockingSession{MemorySession: nexustest.NewMemorySession("slow", nil), entered: make(chan struct{}), gate: make(chan struct{})}
+	takeover(t, n, slow)
+	defer close(slow.gate)
+
+	// 阻塞在写出中的 Send 持有 writeLock
+	go func() { _ = n.Send("slow", []byte("stuck")) }()
+	<-slow.entered
+
+	var broadcastErr error
+	start := time.Now()
+	n.Broadcast([]byte("hello"), func(sessionId string, sessionContext nexus.SessionContext, err error) bool {
+		broadcastErr = err
+		return false
+	})
+	elapsed := time.Since(start)
+	if !errors.Is(broadcastErr, nexus.ErrSendTimeout) {
+		t.Fatalf("broadcast error = %v, want ErrSendTimeout", broadcastErr)
+	}
+	if elapsed < timeout || elapsed > timeout+testTimeout/2 {
+		t.Fatalf("broadcast took %s, want about %s", elapsed, timeout)
+	}
+}
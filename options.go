@@ -1,5 +1,22 @@
 package nexus
 
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kercylan98/vivid"
+)
+
+// SessionErrorHandler 在会话因错误而结束时被调用：被拒绝、启动失败，或 MessageErrorSessionActor.OnMessageE 返回错误。
+//
+// 参数：session 为出错的会话，调用时其底层连接已被关闭或正在关闭；err 为具体原因。
+// 该回调在 Nexus Actor 或对应会话的邮箱线程中执行，不应阻塞。
+type SessionErrorHandler = func(session Session, err error)
+
+// defaultReadRetryInterval 为 Options.ReadRetryInterval 未设置时的默认重试间隔。
+const defaultReadRetryInterval = 10 * time.Millisecond
+
 // Option 是用于配置 Options 的函数类型。
 //
 // 通常通过 WithOptions、WithSessionReaderProvider 等构造函数注入；
@@ -26,33 +43,785 @@ func NewOptions(opts ...Option) *Options {
 // 可通过 WithSessionReaderProvider 覆盖。使用 WithOptions 克隆时，若源 Options 的该字段为 nil，会补回默认实现。
 type Options struct {
 	SessionReaderProvider SessionReaderProvider
+
+	// WriteBufferFlushThreshold 为 SessionContext.BufferWrite 的自动 Flush 阈值（字节），
+	// 缓冲区累计大小达到该值时自动写出；为 0 时不自动 Flush，仅在显式调用 Flush 时写出。
+	WriteBufferFlushThreshold int
+
+	// BroadcastReadyOnly 为 true 时，Broadcast/SendTo 会跳过尚未就绪（OnConnected 未完成或正在关闭）的会话。
+	BroadcastReadyOnly bool
+
+	// SendQueueSize 为每个会话出站队列的容量；大于 0 时 Send 仅将消息入队，由会话独立的写循环异步写出；
+	// 为 0 时不启用队列，Send 同步写入底层 Session。
+	SendQueueSize int
+
+	// BroadcastSendTimeout 为 Broadcast/SendTo 等扇出发送中单个会话同步写出的最长耗时，超时的会话被跳过并以 ErrSendTimeout 报告；
+	// 为 0 时不限制。
+	BroadcastSendTimeout time.Duration
+
+	// BroadcastConcurrency 为 Broadcast/SendTo 等扇出发送的并行 goroutine 数量；小于等于 1 时在调用方 goroutine 中顺序发送。
+	BroadcastConcurrency int
+
+	// BroadcastPauseQueueSize 为 PauseBroadcast 期间最多暂存的广播次数，ResumeBroadcast 时按顺序重放；
+	// 为 0 时暂停期间的广播直接丢弃，超出容量的广播同样被丢弃。
+	BroadcastPauseQueueSize int
+
+	// OutboundRateLimit 为每个会话每秒允许写出的出站字节数；为 0 时不限制。
+	OutboundRateLimit int
+
+	// OutboundBurst 为出站限流允许的突发字节数，小于等于 0 时取 OutboundRateLimit。
+	OutboundBurst int
+
+	// DrainOnKillTimeout 为 Nexus 被 Kill 时等待所有会话完成关闭的最长时间；为 0 时不等待。
+	DrainOnKillTimeout time.Duration
+
+	// HandlerTimeout 为业务处理单条入站消息的最长耗时，超时后会话会被 Kill；为 0 时不限制。
+	HandlerTimeout time.Duration
+
+	// MessageBatchSize 为批量投递入站消息时单批的最大条数；为 0 时不启用批量投递，逐条调用 OnMessage。
+	MessageBatchSize int
+
+	// MessageBatchWait 为批量投递时自收到首条消息起等待凑批的最长时间；为 0 时仅合并已读取到的消息，不额外等待。
+	MessageBatchWait time.Duration
+
+	// InboundDedupWindow 为入站消息去重窗口，窗口内键相同的重复消息会在投递给业务前被丢弃；为 0 时不去重。
+	InboundDedupWindow time.Duration
+
+	// InboundDedupKey 计算入站消息的去重键；为 nil 时以消息内容本身作为键。
+	InboundDedupKey func(message []byte) string
+
+	// MaxMessageSize 为单条入站消息的最大字节数，超出时会话以 ErrFrameTooLarge 关闭，并会下发给实现 ReadLimiter 的 Session；为 0 时不限制。
+	MaxMessageSize int64
+
+	// AckEncoder 将 SendWithAck 分配的序号编码进出站消息；与 AckExtractor 同时设置时启用确认机制。
+	AckEncoder AckEncoder
+
+	// AckExtractor 从入站消息中解析客户端确认的序号；与 AckEncoder 同时设置时启用确认机制。
+	AckExtractor AckExtractor
+
+	// ConnectCloseTimeout 为 SessionActor.OnConnected 执行超过该时间时关闭底层 Session 的期限，OnConnected 返回后会话被 Kill；为 0 时不限制。
+	// 它不会中断 OnConnected，也不会释放会话邮箱。
+	ConnectCloseTimeout time.Duration
+
+	// HandshakeTimeout 为会话启动后到调用 SessionContext.MarkHandshaked 的最长时间，超时未完成握手的会话会被 Kill；为 0 时不限制。
+	HandshakeTimeout time.Duration
+
+	// ReadRetryInterval 为 SessionReader 返回 ErrNoDataYet（或 (0, nil, nil)）后到下一次 Read 的等待时间；
+	// 为 0 时使用默认值 defaultReadRetryInterval。
+	ReadRetryInterval time.Duration
+
+	// EOFPolicy 为读循环读到 EOF 时的处理策略，零值 EOFPolicyClose 表示立即关闭会话。
+	EOFPolicy EOFPolicy
+
+	// EOFGracePeriod 为 EOFPolicyGrace 下会话等待恢复的宽限期，EOFPolicy 为 EOFPolicyGrace 时必须大于 0。
+	EOFGracePeriod time.Duration
+
+	// MailboxFullPolicy 为上一条入站消息处理完成前又读到新消息时的处理策略，零值 MailboxFullPolicyBlock 表示暂停读取。
+	MailboxFullPolicy MailboxFullPolicy
+
+	// MailboxFullHandler 在 MailboxFullPolicyDrop 下丢弃入站消息时调用，message 仅在调用期间有效；为 nil 时静默丢弃。
+	MailboxFullHandler func(sessionId string, message []byte)
+
+	// SlowWriteThreshold 为慢写出判定阈值，单次写入底层 Session 的耗时达到该值时调用 SlowWriteHandler；为 0 时不判定。
+	SlowWriteThreshold time.Duration
+
+	// SlowWriteHandler 在单次写出耗时达到 SlowWriteThreshold 时调用；为 nil 时不计时。
+	SlowWriteHandler func(sessionId string, size int, elapsed time.Duration)
+
+	// DisableReadLoop 为 true 时不为会话启动读循环，也不调用 SessionReaderProvider，适用于仅推送的会话；
+	// 此时会话仅由显式关闭或写出失败结束。
+	DisableReadLoop bool
+
+	// ReadBufferGet 为默认 SessionReader 获取读缓冲区的函数，缓冲区的容量即单次读取的上限；为 nil 时由 Nexus 自行分配 4KB 缓冲区。
+	ReadBufferGet func() []byte
+
+	// ReadBufferPut 在会话结束后归还由 ReadBufferGet 获取的缓冲区，可为 nil。
+	ReadBufferPut func(buf []byte)
+
+	// InitialSessionCapacity 为会话表的初始容量，预计托管大量会话时可避免启动阶段的反复扩容；为 0 时不预分配。
+	InitialSessionCapacity int
+
+	// AcceptFunc 为接管会话前的准入函数，返回非 nil error 时会话被关闭且不创建 sessionActor；为 nil 时不检查。
+	AcceptFunc func(session Session) error
+
+	// AcceptRateLimit 为每秒允许接管的新会话数量，超出的会话会被关闭并以 ErrAcceptRateLimited 通知；为 0 时不限制。
+	AcceptRateLimit int
+
+	// AcceptBurst 为接管限流允许的突发数量，小于等于 0 时取 AcceptRateLimit。
+	AcceptBurst int
+
+	// AuditHook 在每条入站消息交给业务处理前调用，用于审计留存；为 nil 时不审计。
+	AuditHook func(sessionId string, message []byte)
+
+	// AuditSampleRate 为 AuditHook 的采样比例，取值 [0, 1]；为 0 时视为未设置，与 1 相同审计全部入站消息。
+	// 不需要审计时应不设置 AuditHook，而不是将采样比例设为 0。
+	AuditSampleRate float64
+
+	// TimerJitter 为内部定时器（处理超时、读重试间隔等）的随机抖动比例，取值 [0, 1)；为 0 时不抖动。
+	TimerJitter float64
+
+	// EventBufferSize 为 Nexus.Events 返回的生命周期事件通道容量，通道满时新事件会被丢弃；为 0 时使用默认值 1024。
+	EventBufferSize int
+
+	// StreamChunkSize 为 SendStream 每次从 io.Reader 读取并写出的分块大小（字节）；为 0 时使用默认值 32KiB。
+	StreamChunkSize int
+
+	// StreamTimeout 为单次 SendStream 的最长耗时（含等待 writeLock、从 io.Reader 读取与写出），超时返回 ErrSendTimeout；为 0 时不限制。
+	StreamTimeout time.Duration
+
+	// ShutdownOrder 为 Nexus 重启或被 Kill 时关闭所有会话的顺序；为 ShutdownOrderNone 时顺序不固定。
+	ShutdownOrder ShutdownOrder
+
+	// OutboundFramer 为出站消息的分帧方式，Send 等发送的每条消息会先经其编码再写出；为 nil 时原样写出。
+	OutboundFramer Framer
+
+	// SpawnOptions 为每个会话提供额外的 vivid.ActorOption，在创建 sessionActor 时使用；为 nil 时不追加。
+	SpawnOptions func(session Session) []vivid.ActorOption
+
+	// SessionIdGenerator 在接管的会话 GetSessionId 返回空字符串时生成 sessionId；为 nil 时不生成。
+	SessionIdGenerator func() string
+
+	// SessionIdValidator 在接管会话、创建 sessionActor 前校验 sessionId，返回 error 时拒绝该会话；为 nil 时不校验。
+	SessionIdValidator func(sessionId string) error
+
+	// ResetHandler 在 Nexus Actor 启动或被 Kill 而批量清理会话后调用，count 为被 Kill 的会话数量，reason 为 ResetReasonLaunch 或 ResetReasonShutdown；
+	// 为 nil 时不回调。
+	ResetHandler func(count int, reason string)
+
+	// ProvideNilHandler 在 SessionActorProvider 返回 nil 时调用，此时底层 Session 已被关闭；为 nil 时不回调。
+	ProvideNilHandler func(session Session)
+
+	// ReasonFormatter 将 Nexus Kill 会话时的关闭原因格式化为字符串；为 nil 时使用 "reason: detail" 的默认格式。
+	ReasonFormatter ReasonFormatter
+
+	// SessionErrorHandler 在会话被拒绝、启动失败或处理消息返回错误时调用；为 nil 时仅记录日志。
+	SessionErrorHandler SessionErrorHandler
+
+	rejected []error // With* 收到的非法参数（如为 nil 的函数），由 Validate 统一报告
+}
+
+// Validate 校验 Options 的配置是否合法，存在问题时返回描述性的错误。
+//
+// New 会在构造 Nexus 前调用该函数，校验失败时直接返回错误，而不是构造一个运行期才暴露问题的实例。
+func (o *Options) Validate() error {
+	if o == nil {
+		return errors.New("options is nil")
+	}
+	if len(o.rejected) > 0 {
+		return errors.Join(o.rejected...)
+	}
+	if o.SessionReaderProvider == nil {
+		return errors.New("options: session reader provider is nil")
+	}
+	if o.WriteBufferFlushThreshold < 0 {
+		return fmt.Errorf("options: write buffer flush threshold must be non-negative, got %d", o.WriteBufferFlushThreshold)
+	}
+	if o.SendQueueSize < 0 {
+		return fmt.Errorf("options: send queue size must be non-negative, got %d", o.SendQueueSize)
+	}
+	if o.InitialSessionCapacity < 0 {
+		return fmt.Errorf("options: initial session capacity must be non-negative, got %d", o.InitialSessionCapacity)
+	}
+	if o.AcceptRateLimit < 0 || o.AcceptBurst < 0 {
+		return fmt.Errorf("options: accept rate limit must be non-negative, got %d/s burst %d", o.AcceptRateLimit, o.AcceptBurst)
+	}
+	if o.BroadcastSendTimeout < 0 {
+		return fmt.Errorf("options: broadcast send timeout must be non-negative, got %s", o.BroadcastSendTimeout)
+	}
+	if o.BroadcastConcurrency < 0 {
+		return fmt.Errorf("options: broadcast concurrency must be non-negative, got %d", o.BroadcastConcurrency)
+	}
+	if o.BroadcastPauseQueueSize < 0 {
+		return fmt.Errorf("options: broadcast pause queue size must be non-negative, got %d", o.BroadcastPauseQueueSize)
+	}
+	if o.OutboundRateLimit < 0 || o.OutboundBurst < 0 {
+		return fmt.Errorf("options: outbound rate limit must be non-negative, got %d B/s burst %d", o.OutboundRateLimit, o.OutboundBurst)
+	}
+	if o.AuditSampleRate < 0 || o.AuditSampleRate > 1 {
+		return fmt.Errorf("options: audit sample rate must be in [0, 1], got %v", o.AuditSampleRate)
+	}
+	if o.TimerJitter < 0 || o.TimerJitter >= 1 {
+		return fmt.Errorf("options: timer jitter must be in [0, 1), got %v", o.TimerJitter)
+	}
+	if o.EventBufferSize < 0 {
+		return fmt.Errorf("options: event buffer size must be non-negative, got %d", o.EventBufferSize)
+	}
+	if o.StreamChunkSize < 0 {
+		return fmt.Errorf("options: stream chunk size must be non-negative, got %d", o.StreamChunkSize)
+	}
+	if o.StreamTimeout < 0 {
+		return fmt.Errorf("options: stream timeout must be non-negative, got %s", o.StreamTimeout)
+	}
+	if o.DrainOnKillTimeout < 0 {
+		return fmt.Errorf("options: drain on kill timeout must be non-negative, got %s", o.DrainOnKillTimeout)
+	}
+	if o.ReadRetryInterval < 0 {
+		return fmt.Errorf("options: read retry interval must be non-negative, got %s", o.ReadRetryInterval)
+	}
+	if o.HandlerTimeout < 0 {
+		return fmt.Errorf("options: handler timeout must be non-negative, got %s", o.HandlerTimeout)
+	}
+	if o.MessageBatchSize < 0 || o.MessageBatchWait < 0 {
+		return fmt.Errorf("options: message batching must be non-negative, got %d within %s", o.MessageBatchSize, o.MessageBatchWait)
+	}
+	if o.ShutdownOrder > ShutdownOrderNewestFirst {
+		return fmt.Errorf("options: unknown shutdown order %d", o.ShutdownOrder)
+	}
+	if o.InboundDedupWindow < 0 {
+		return fmt.Errorf("options: inbound dedup window must be non-negative, got %s", o.InboundDedupWindow)
+	}
+	if o.MaxMessageSize < 0 {
+		return fmt.Errorf("options: max message size must be non-negative, got %d", o.MaxMessageSize)
+	}
+	if o.MailboxFullPolicy > MailboxFullPolicyClose {
+		return fmt.Errorf("options: invalid mailbox full policy %d", o.MailboxFullPolicy)
+	}
+	if o.MailboxFullPolicy != MailboxFullPolicyBlock && o.MessageBatchSize > 0 {
+		return errors.New("options: mailbox full policy cannot be combined with message batching")
+	}
+	if o.EOFPolicy > EOFPolicyGrace {
+		return fmt.Errorf("options: invalid eof policy %d", o.EOFPolicy)
+	}
+	if o.EOFGracePeriod < 0 || (o.EOFPolicy == EOFPolicyGrace && o.EOFGracePeriod == 0) {
+		return fmt.Errorf("options: eof grace period must be positive with EOFPolicyGrace, got %s", o.EOFGracePeriod)
+	}
+	if o.SlowWriteThreshold < 0 {
+		return fmt.Errorf("options: slow write threshold must be non-negative, got %s", o.SlowWriteThreshold)
+	}
+	if o.ConnectCloseTimeout < 0 {
+		return fmt.Errorf("options: connect close timeout must be non-negative, got %s", o.ConnectCloseTimeout)
+	}
+	if o.HandshakeTimeout < 0 {
+		return fmt.Errorf("options: handshake timeout must be non-negative, got %s", o.HandshakeTimeout)
+	}
+	return nil
+}
+
+// reject 记录 option 收到的非法参数，由 Validate 报告。
+func (o *Options) reject(option, reason string) {
+	o.rejected = append(o.rejected, fmt.Errorf("options: %s: %s", option, reason))
 }
 
 // WithOptions 将给定的 Options 整体复制到构建中的 Options。
 //
-// 用于从已有配置克隆或批量设置。若 options 为 nil 则不修改目标；
+// 用于从已有配置克隆或批量设置。若 options 为 nil 则不修改目标，并在 Validate 时报错；
 // 若 options.SessionReaderProvider 为 nil，会先赋默认实现再复制，避免目标得到 nil Provider。
 func WithOptions(options *Options) Option {
 	return func(opts *Options) {
 		if options == nil {
+			opts.reject("WithOptions", "options is nil")
 			return
 		}
 		if options.SessionReaderProvider == nil {
 			options.SessionReaderProvider = SessionReaderProviderFN(newDefaultSessionReader)
 		}
+		rejected := append(opts.rejected[:len(opts.rejected):len(opts.rejected)], options.rejected...)
 		*opts = *options
+		opts.rejected = rejected
 	}
 }
 
 // WithSessionReaderProvider 设置会话数据读取器提供方。
 //
 // 每个 Session 在 Prelaunch 时通过该 Provider 获取对应的 SessionReader。
-// 若 provider 为 nil 则本 Option 不修改 Options（保留原有或默认值）。
+// 若 provider 为 nil 则本 Option 不修改 Options（保留原有或默认值），并在 Validate 时报错。
 func WithSessionReaderProvider(provider SessionReaderProvider) Option {
 	return func(o *Options) {
 		if provider == nil {
+			o.reject("WithSessionReaderProvider", "provider is nil")
 			return
 		}
 		o.SessionReaderProvider = provider
 	}
 }
+
+// WithWriteBufferFlushThreshold 设置 SessionContext.BufferWrite 的自动 Flush 阈值（字节）。
+//
+// 缓冲区累计大小达到 threshold 时自动写出；为 0 时仅在显式 Flush 时写出，负数会在 Validate 时报错。
+func WithWriteBufferFlushThreshold(threshold int) Option {
+	return func(o *Options) {
+		o.WriteBufferFlushThreshold = threshold
+	}
+}
+
+// WithBroadcastReadyOnly 设置 Broadcast/SendTo 是否仅向已就绪的会话发送。
+//
+// 会话在 OnConnected 完成后被标记为就绪，在开始关闭时清除；readyOnly 为 true 时，
+// 未就绪的会话会被静默跳过，不会触发 SendErrorHandler。Send 不受该配置影响。
+func WithBroadcastReadyOnly(readyOnly bool) Option {
+	return func(o *Options) {
+		o.BroadcastReadyOnly = readyOnly
+	}
+}
+
+// WithDrainOnKill 设置 Nexus 被 Kill 时等待所有会话完成关闭的最长时间。
+//
+// 启用后，Nexus 在 OnKill 中 Kill 所有会话后会阻塞等待它们完成 OnDisconnected 与 Session.Close，
+// 最长等待 timeout，以保证受控停机时告别消息能够写出；为 0 时不等待（默认），负数会在 Validate 时报错。
+func WithDrainOnKill(timeout time.Duration) Option {
+	return func(o *Options) {
+		o.DrainOnKillTimeout = timeout
+	}
+}
+
+// WithSessionIdValidator 设置 sessionId 校验函数。
+//
+// validator 在接管会话、创建 sessionActor 前调用；返回 error 时该会话会被关闭，并通过 SessionErrorHandler 通知。
+// 用于在所有接入层上统一拒绝空的或格式错误的 sessionId。若 validator 为 nil 则不修改 Options，并在 Validate 时报错。
+func WithSessionIdValidator(validator func(sessionId string) error) Option {
+	return func(o *Options) {
+		if validator == nil {
+			o.reject("WithSessionIdValidator", "validator is nil")
+			return
+		}
+		o.SessionIdValidator = validator
+	}
+}
+
+// WithSessionIdGenerator 设置 sessionId 生成器，用于接管不携带 ID 的匿名连接。
+//
+// 接管的会话 GetSessionId 返回空字符串时，Nexus 调用 generator 生成 ID，并以 NewSessionWithId 包装后托管，
+// 此后回调中的 SessionContext.GetSessionId 及 Send、Close 等寻址均使用生成的 ID。生成的 ID 仍会经过 SessionIdValidator 校验，
+// 应保证唯一，重复时会替换已有会话；可使用 NewSessionIdGenerator 生成 UUID 格式的 ID。为 nil 时不修改 Options，并在 Validate 时报错。
+func WithSessionIdGenerator(generator func() string) Option {
+	return func(o *Options) {
+		if generator == nil {
+			o.reject("WithSessionIdGenerator", "generator is nil")
+			return
+		}
+		o.SessionIdGenerator = generator
+	}
+}
+
+// WithReasonFormatter 设置会话关闭原因的格式化函数，用于本地化或结构化（如输出 JSON）关闭原因，便于日志与指标解析。
+//
+// formatter 接收 Reason 常量与可选的细节（如错误信息），返回值作为 Kill 的原因字符串。为 nil 时不修改 Options，并在 Validate 时报错。
+func WithReasonFormatter(formatter ReasonFormatter) Option {
+	return func(o *Options) {
+		if formatter == nil {
+			o.reject("WithReasonFormatter", "formatter is nil")
+			return
+		}
+		o.ReasonFormatter = formatter
+	}
+}
+
+// WithShutdownOrder 设置 Nexus 重启或被 Kill 时关闭所有会话的顺序。
+//
+// 默认（ShutdownOrderNone）按 map 遍历的随机顺序关闭；ShutdownOrderOldestFirst 与 ShutdownOrderNewestFirst 按会话接管时间排序后
+// 依次发出关闭，适用于需要可预期拆除顺序的分层服务。关闭本身是异步的，顺序仅保证 Kill 的发出顺序。
+func WithShutdownOrder(order ShutdownOrder) Option {
+	return func(o *Options) {
+		o.ShutdownOrder = order
+	}
+}
+
+// WithOutboundFraming 设置出站消息的分帧方式，使请求与响应使用一致的帧格式，业务无需手动添加长度前缀或分隔符。
+//
+// 配置后 Send、SendWait、SendWithPriority、SendText/SendBinary、SendAndClose 及各类广播发送的每条消息都会先经 framer 编码再写出，
+// 编码失败时返回该错误；BufferWrite/Flush 与 SendStream 写出的是原始字节流，不经过分帧。可与 NewLengthPrefixFramer、
+// NewDelimiterFramer 配合使用，也可自定义 Framer。为 nil 时不修改 Options，并在 Validate 时报错。
+func WithOutboundFraming(framer Framer) Option {
+	return func(o *Options) {
+		if framer == nil {
+			o.reject("WithOutboundFraming", "framer is nil")
+			return
+		}
+		o.OutboundFramer = framer
+	}
+}
+
+// WithSessionErrorHandler 设置会话因错误而结束时的回调，触发时机见 SessionErrorHandler。
+//
+// 若 handler 为 nil 则不修改 Options，并在 Validate 时报错。
+func WithSessionErrorHandler(handler SessionErrorHandler) Option {
+	return func(o *Options) {
+		if handler == nil {
+			o.reject("WithSessionErrorHandler", "handler is nil")
+			return
+		}
+		o.SessionErrorHandler = handler
+	}
+}
+
+// WithHandlerTimeout 设置业务处理单条入站消息的最长耗时。
+//
+// readLoop 在投递消息后最长等待 timeout，若 OnMessage 仍未返回则以 "session handler timeout" 为原因 Kill 会话，
+// 避免业务死锁导致读循环 goroutine 永久泄漏；为 0 时不限制（默认），负数会在 Validate 时报错。
+func WithHandlerTimeout(timeout time.Duration) Option {
+	return func(o *Options) {
+		o.HandlerTimeout = timeout
+	}
+}
+
+// WithMessageBatching 启用入站消息批量投递，适用于高频小包（如传感器遥测）等逐条投递开销过大的场景。
+//
+// 启用后读循环在独立 goroutine 中预读消息，凑满 maxBatch 条或自首条消息起等待 maxWait 后整批投递到邮箱，批内保持读取顺序；
+// 业务实现 BatchSessionActor 时以 OnMessages 一次性接收整批，否则仍逐条调用 OnMessage。由于存在预读，批内消息均为拷贝。
+// HandlerTimeout 在启用时按批计算。maxBatch 为 0 时不启用（默认），负数会在 Validate 时报错。
+func WithMessageBatching(maxBatch int, maxWait time.Duration) Option {
+	return func(o *Options) {
+		o.MessageBatchSize = maxBatch
+		o.MessageBatchWait = maxWait
+	}
+}
+
+// WithInboundDedup 启用入站消息去重，用于不可靠传输重复投递同一帧的场景。
+//
+// 每个会话独立记录 window 内见过的消息键，键由 keyFn 计算（为 nil 时以消息内容作为键），重复的消息在 Ask 等待者匹配与
+// OnMessage 之前被丢弃。内存占用与每个会话在 window 内收到的不同消息数量成正比，高频场景应让 keyFn 返回较短的键（如消息序号）
+// 并使用较短的 window。window 为 0 时不去重（默认），负数会在 Validate 时报错。
+func WithInboundDedup(window time.Duration, keyFn func(message []byte) string) Option {
+	return func(o *Options) {
+		o.InboundDedupWindow = window
+		o.InboundDedupKey = keyFn
+	}
+}
+
+// WithMaxMessageSize 设置单条入站消息的最大字节数。
+//
+// SessionReader 返回的消息超过 size 时会话以 ErrFrameTooLarge 为原因关闭；底层 Session 实现 ReadLimiter 时，
+// 上限还会在读循环启动前下发到传输层，使超限消息在协议层被提前拒绝。为 0 时不限制（默认），负数会在 Validate 时报错。
+func WithMaxMessageSize(size int64) Option {
+	return func(o *Options) {
+		o.MaxMessageSize = size
+	}
+}
+
+// WithAck 启用出站消息确认机制，用于在弱网等链路上实现至少一次的可靠推送。
+//
+// SendWithAck 为每条消息分配会话内单调递增的序号并以 encoder 编码后写出；入站消息经 extractor 解析为确认时标记对应序号已确认，
+// 该确认消息不再投递给 Ask 等待者与 OnMessage，WaitAck 据此返回。重发策略由业务根据 WaitAck 的结果决定。
+// encoder 或 extractor 为 nil 时不修改 Options，并在 Validate 时报错。
+func WithAck(encoder AckEncoder, extractor AckExtractor) Option {
+	return func(o *Options) {
+		if encoder == nil || extractor == nil {
+			o.reject("WithAck", "encoder or extractor is nil")
+			return
+		}
+		o.AckEncoder = encoder
+		o.AckExtractor = extractor
+	}
+}
+
+// WithConnectCloseTimeout 设置 OnConnected 执行超时后关闭连接的期限：SessionActor.OnConnected 超过 timeout 仍未返回时关闭底层 Session。
+//
+// 读循环总是在 OnConnected 返回后才启动，入站数据不会与 OnConnected 并发处理；但 OnConnected 中的大量同步写出
+// 会阻塞会话邮箱。超过 timeout 时 Nexus 关闭底层 Session，使 OnConnected 中阻塞于该连接的读写以错误返回；OnConnected 返回后
+// 会话以 ReasonConnectTimeout 被 Kill，且不会启动读循环。
+//
+// 这是针对连接的保护而非对 OnConnected 的超时：OnConnected 运行在会话邮箱线程中且无法被中断，超时后仍会继续执行直到返回，
+// 期间邮箱依旧被占用，Kill、TellLater 等消息要到 OnConnected 返回后才会处理。不阻塞于该连接 I/O 的耗时逻辑（如等待外部服务）
+// 应自行以 context 等方式限时。为 0 时不限制（默认），负数会在 Validate 时报错。
+func WithConnectCloseTimeout(timeout time.Duration) Option {
+	return func(o *Options) {
+		o.ConnectCloseTimeout = timeout
+	}
+}
+
+// WithHandshakeTimeout 设置会话完成握手的最长时间。
+//
+// 会话 Actor 启动时开始计时，业务在认证等握手流程完成后调用 SessionContext.MarkHandshaked 标记握手完成；
+// 超过 timeout 仍未标记的会话会以 "session handshake timeout" 为原因被 Kill，用于限制只建连不认证的连接的存活时间。
+// 为 0 时不限制（默认），负数会在 Validate 时报错。
+func WithHandshakeTimeout(timeout time.Duration) Option {
+	return func(o *Options) {
+		o.HandshakeTimeout = timeout
+	}
+}
+
+// WithReadRetryInterval 设置 SessionReader 暂无数据时的重试间隔。
+//
+// 适用于基于非阻塞传输的 Reader：返回 ErrNoDataYet 后 readLoop 等待 interval 再次 Read，避免空转占用 CPU。
+// 为 0 时使用默认值（10ms），负数会在 Validate 时报错。
+func WithReadRetryInterval(interval time.Duration) Option {
+	return func(o *Options) {
+		o.ReadRetryInterval = interval
+	}
+}
+
+// WithSendQueue 为每个会话启用容量为 size 的出站优先级队列。
+//
+// 启用后 Send、SendTo、Broadcast 仅拷贝消息并入队，由会话独立的写循环按优先级写出，队列已满时返回 ErrSendQueueFull；
+// 写出错误不再同步返回，需要确认投递结果时使用 SendWait。写循环在会话被接管时即已启动，sessionActor 启动前入队的消息同样会被写出；优雅关闭时仍在排队的消息（包括 OnDisconnected 中发送的）会在关闭底层 Session 前
+// 按序写出，ForceClose 与 SendAndClose 时被丢弃。
+// 为 0 时不启用（默认），负数会在 Validate 时报错。
+func WithSendQueue(size int) Option {
+	return func(o *Options) {
+		o.SendQueueSize = size
+	}
+}
+
+// WithBroadcastSendTimeout 设置扇出发送中单个会话同步写出的最长耗时，避免一个慢速客户端拖慢整个广播。
+//
+// 作用于 Broadcast、BroadcastShared、BroadcastContext、SendTo 及基于它们的房间、标签等扇出发送：等待会话 writeLock 超过 timeout
+// 时放弃该会话；底层 Session 实现了 WriteDeadlineSession 时，写出本身也以剩余时间为写超时，写出后写超时被重置为零值，
+// 因此启用后不应再自行设置该 Session 的写超时。超时的会话被跳过，并以包装了 ErrSendTimeout
+// 的错误交给 SendErrorHandler，广播继续发送后续会话，因此单次广播的总耗时约为 timeout 乘以慢速会话数量。
+// 写超时后底层连接可能已写出部分数据，需要时可在 SendErrorHandler 中关闭该会话。逐会话的 Send 不受影响；启用 WithSendQueue 时
+// 入队本身不会阻塞，该选项不生效。为 0 时不限制（默认），负数会在 Validate 时报错。
+func WithBroadcastSendTimeout(timeout time.Duration) Option {
+	return func(o *Options) {
+		o.BroadcastSendTimeout = timeout
+	}
+}
+
+// WithBroadcastConcurrency 设置扇出发送的并行度，使 Broadcast 的耗时不再随会话数量线性增长。
+//
+// 作用于 Broadcast、BroadcastShared、SendTo 及基于它们的房间、标签等扇出发送：目标会话去重后由 n 个 goroutine 并行写出，
+// 每个会话的写入仍由其自身的写锁串行化，因此单个会话内的消息顺序不变，但不同会话之间的写出顺序不再固定。
+// SendErrorHandler 的调用会被串行化，语义不变；某次 handler 返回 true 后不再领取新的会话，但已在写出中的会话仍会完成。
+// 扇出调用会阻塞至所有会话发送完毕。BroadcastContext 不受影响。小于等于 1 时顺序发送（默认），负数会在 Validate 时报错。
+func WithBroadcastConcurrency(n int) Option {
+	return func(o *Options) {
+		o.BroadcastConcurrency = n
+	}
+}
+
+// WithBroadcastPauseQueue 设置 PauseBroadcast 期间最多暂存的广播次数。
+//
+// 暂存的广播会拷贝 message（BroadcastShared 除外），并在 ResumeBroadcast 时向暂停时确定的目标会话按原顺序重放；超出容量的广播被丢弃。
+// 为 0 时暂停期间的广播直接丢弃（默认），负数会在 Validate 时报错。
+func WithBroadcastPauseQueue(size int) Option {
+	return func(o *Options) {
+		o.BroadcastPauseQueueSize = size
+	}
+}
+
+// WithOutboundRateLimit 为每个会话启用出站字节限流，避免服务端压垮慢速客户端或在其连接上无限缓冲。
+//
+// 每个会话拥有独立的令牌桶，每秒补充 bytesPerSec 个字节令牌、容量为 burst（小于等于 0 时取 bytesPerSec），按消息长度扣减，
+// 单条消息超过 burst 时在满桶时放行。未启用出站队列时，Send 等同步发送在令牌不足时立即返回 ErrRateLimited；
+// 启用 WithSendQueue 时，写出协程会等待令牌补足再写出，消息在队列中积压，队列满后 Send 返回 ErrSendQueueFull。
+// 限流仅作用于逐条发送的消息，SendAndClose、SendStream 与 BufferWrite/Flush 不受限制。为 0 时不限制（默认），负数会在 Validate 时报错。
+func WithOutboundRateLimit(bytesPerSec, burst int) Option {
+	return func(o *Options) {
+		o.OutboundRateLimit = bytesPerSec
+		o.OutboundBurst = burst
+	}
+}
+
+// WithSlowWrite 设置慢写出检测，单次写入底层 Session 的耗时达到 threshold 时调用 handler。
+//
+// 慢写出通常意味着客户端接收拥塞，可作为驱逐慢客户端的依据。计时覆盖 Send、广播、出站队列等所有逐条写出，size 为本次写出的字节数。
+// handler 在写出路径上同步调用（可能持有会话写锁），不应阻塞，也不应在其中向同一会话发送消息；如需关闭会话，可异步调用 Nexus.Close。
+// 仅在 handler 非 nil 且 threshold 大于 0 时计时，未配置时不影响写出路径；threshold 为负数会在 Validate 时报错。若 handler 为 nil 则不修改 Options，并在 Validate 时报错。
+func WithSlowWrite(threshold time.Duration, handler func(sessionId string, size int, elapsed time.Duration)) Option {
+	return func(o *Options) {
+		if handler == nil {
+			o.reject("WithSlowWrite", "handler is nil")
+			return
+		}
+		o.SlowWriteThreshold = threshold
+		o.SlowWriteHandler = handler
+	}
+}
+
+// WithSpawnOptions 设置按会话提供额外 vivid.ActorOption 的函数。
+//
+// provider 在 Nexus 为会话创建 sessionActor 时调用，返回的选项会传给 ActorOf，
+// 可按会话类别调整邮箱、调度器等 vivid 参数（如为高吞吐连接配置更大的邮箱）。若 provider 为 nil 则不修改 Options，并在 Validate 时报错。
+func WithSpawnOptions(provider func(session Session) []vivid.ActorOption) Option {
+	return func(o *Options) {
+		if provider == nil {
+			o.reject("WithSpawnOptions", "provider is nil")
+			return
+		}
+		o.SpawnOptions = provider
+	}
+}
+
+// WithMailboxFullPolicy 设置读循环读到新的入站消息、而上一条消息仍在会话邮箱中等待或处理时的处理策略。
+//
+// MailboxFullPolicyBlock（默认）时读循环等待上一条消息处理完成再继续读取，数据滞留在传输层。MailboxFullPolicyDrop 时读循环不再等待，
+// 处理期间读到的消息被丢弃并交给 handler（可为 nil）；MailboxFullPolicyClose 时会话以 ReasonMailboxFull 关闭。
+// 非 MailboxFullPolicyBlock 策略下读循环不等待处理完成，HandlerTimeout 不生效；该类策略不能与 WithMessageBatching 同时使用。
+// policy 非法或与 WithMessageBatching 同时使用时会在 Validate 时报错。
+func WithMailboxFullPolicy(policy MailboxFullPolicy, handler func(sessionId string, message []byte)) Option {
+	return func(o *Options) {
+		o.MailboxFullPolicy = policy
+		o.MailboxFullHandler = handler
+	}
+}
+
+// WithEOFPolicy 设置读循环读到 EOF（对端正常关闭连接）时的处理策略。
+//
+// EOFPolicyClose（默认）时 EOF 即关闭会话。EOFPolicyGrace 适用于可断线续连的传输：EOF 后会话进入时长为 grace 的宽限期，
+// sessionActor、元数据与出站队列均被保留，会话暂不就绪（BroadcastReadyOnly 下不再接收广播）；宽限期内同 ID 的新连接被接管时，
+// Nexus 不再替换会话，而是将新连接交给原会话继续读写，并调用 ResumedSessionActor.OnResumed，此时准入检查照常执行，
+// 但新连接携带的元数据、SessionKey 与 TakeoverSessionWithAttrs 的属性不会生效。宽限期结束仍未恢复时会话以 ReasonResumeExpired 关闭，
+// OnDisconnected 此时才被调用；宽限期结束后到达的新连接，以及原会话在恢复前因其他原因关闭时交给它的新连接，均作为新会话接管。宽限期内的写出会作用于已断开的连接并返回错误。读取错误、Close 等其他关闭原因不受该策略影响。
+// policy 非法或 EOFPolicyGrace 下 grace 不为正数时会在 Validate 时报错。
+func WithEOFPolicy(policy EOFPolicy, grace time.Duration) Option {
+	return func(o *Options) {
+		o.EOFPolicy = policy
+		o.EOFGracePeriod = grace
+	}
+}
+
+// WithReadLoop 设置是否为会话启动读循环，默认启动。
+//
+// 对仅由服务端推送、客户端不发送消息的会话（如通知推送），传入 false 可省去每个会话一个阻塞在 Read 上的 goroutine。
+// 禁用后 Nexus 不会调用 SessionReaderProvider，GetSessionReader 返回 nil，OnMessage 与 PauseReading 等读取相关能力不再生效；
+// 由于无法通过读取感知对端断开，会话仅在显式 Close 等关闭操作或首次写出失败（以 ReasonWriteFailed）时关闭，OnDisconnected 与清理流程照常执行。
+// 没有写出的空闲会话不会自行结束，需要时可结合心跳写出或 HandshakeTimeout 等机制。
+func WithReadLoop(enabled bool) Option {
+	return func(o *Options) {
+		o.DisableReadLoop = !enabled
+	}
+}
+
+// WithReadBufferProvider 设置默认 SessionReader 使用的读缓冲区来源，便于与应用其他子系统共用同一缓冲池并精确控制内存。
+//
+// 每个会话在首次读取时调用一次 get 获取缓冲区，并在整个会话期间复用（容量即单次读取的上限，容量为 0 时退回自行分配）；
+// 会话结束、读取停止且不再有消息处理使用 Read 返回的数据后调用 put 归还。Read 返回的数据仍仅在下一次 Read 前有效。
+// 仅作用于默认 SessionReader，包括经 NewTimeoutReaderProvider、NewTransformReaderProvider 包装的默认 SessionReader；
+// ChainReaders、NewBufferedReaderProvider 等自定义 SessionReader 自行管理缓冲区（可实现 io.Closer 以在会话结束时归还），
+// 此时 get 与 put 不会被调用，Nexus 会在首次遇到这类会话时记录一条警告日志。自定义的包装 Reader 可实现 Unwrap() SessionReader 以便 Nexus 查找。
+// get 与 put 会被多个会话并发调用，需并发安全。若 get 为 nil 则不修改 Options，并在 Validate 时报错。
+func WithReadBufferProvider(get func() []byte, put func(buf []byte)) Option {
+	return func(o *Options) {
+		if get == nil {
+			o.reject("WithReadBufferProvider", "get is nil")
+			return
+		}
+		o.ReadBufferGet = get
+		o.ReadBufferPut = put
+	}
+}
+
+// WithInitialSessionCapacity 设置会话表（及其 ActorRef 索引）的初始容量。
+//
+// 预计托管数万个会话时，按预期规模预分配可避免连接爬升或重连风暴期间会话表反复扩容带来的分配与停顿。
+// 容量在 Nexus Actor 启动及每次重置会话表时生效。为 0 时不预分配（默认），负数会在 Validate 时报错。
+func WithInitialSessionCapacity(n int) Option {
+	return func(o *Options) {
+		o.InitialSessionCapacity = n
+	}
+}
+
+// WithAcceptFunc 设置接管会话前的准入函数，用于 IP 白名单、令牌校验等轻量的准入逻辑。
+//
+// accept 在 Nexus 接管会话的最开始（生成会话 ID 之后、接管限流与 SessionIdValidator 之前）调用，返回非 nil error 时
+// 底层 Session 被立即关闭、不会创建 sessionActor，该 error 原样交由 SessionErrorHandler 处理；相比在 OnConnected 中关闭，
+// 被拒绝的连接不会触及 ActorSystem。accept 运行在 Nexus Actor 的邮箱线程中，会阻塞后续会话的接管，不应执行耗时的 I/O。
+// 若 accept 为 nil 则不修改 Options，并在 Validate 时报错。
+func WithAcceptFunc(accept func(session Session) error) Option {
+	return func(o *Options) {
+		if accept == nil {
+			o.reject("WithAcceptFunc", "accept is nil")
+			return
+		}
+		o.AcceptFunc = accept
+	}
+}
+
+// WithAcceptRateLimit 设置新会话的接管速率限制（准入控制）。
+//
+// 在 Nexus 接管会话、创建 sessionActor 之前按令牌桶检查：每秒补充 perSecond 个名额，最多累积 burst 个；
+// 超出限制的会话会被立即关闭，并以 ErrAcceptRateLimited 交由 SessionErrorHandler 处理，避免上游抖动后的重连风暴冲击 ActorSystem。
+// 该限制只作用于会话接入，与会话内的消息速率无关。perSecond 为 0 时不限制，burst 小于等于 0 时取 perSecond。
+func WithAcceptRateLimit(perSecond int, burst int) Option {
+	return func(o *Options) {
+		o.AcceptRateLimit = perSecond
+		o.AcceptBurst = burst
+	}
+}
+
+// WithOnReset 设置 Nexus 批量清理会话时的回调，用于观测“Nexus 重启”与“Nexus 关闭”这类整体状态变化。
+//
+// Nexus Actor 启动（包括被监督者重启）时以 ResetReasonLaunch、被 Kill 时以 ResetReasonShutdown 调用一次 handler，count 为本次 Kill 的
+// 会话数量（可能为 0）。与逐会话的 SessionEventClosed 不同，每次清理只回调一次，且在所有会话都已发出 Kill 之后调用，
+// 此时会话的 OnDisconnected 可能尚未执行。handler 运行在 Nexus Actor 的邮箱线程中，不应阻塞。若 handler 为 nil 则不修改 Options，并在 Validate 时报错。
+func WithOnReset(handler func(count int, reason string)) Option {
+	return func(o *Options) {
+		if handler == nil {
+			o.reject("WithOnReset", "handler is nil")
+			return
+		}
+		o.ResetHandler = handler
+	}
+}
+
+// WithOnProvideNil 设置 SessionActorProvider 为会话返回 nil 时的回调。
+//
+// 此时会话不会启动，底层 Session 会在回调前被关闭；该回调在会话 Actor 的 Prelaunch 阶段执行，不应阻塞。
+// 若 handler 为 nil 则不修改 Options，并在 Validate 时报错。
+func WithOnProvideNil(handler func(session Session)) Option {
+	return func(o *Options) {
+		if handler == nil {
+			o.reject("WithOnProvideNil", "handler is nil")
+			return
+		}
+		o.ProvideNilHandler = handler
+	}
+}
+
+// WithAuditHook 设置全局入站消息审计回调，在不修改业务处理逻辑的前提下留存入站消息。
+//
+// hook 在会话的邮箱线程中、去重、确认匹配与 OnMessage 之前对每条入站消息调用，只能观察而不能修改或拦截消息。
+// message 的生命周期与 SessionReader 约定一致，需要留存（如异步写入审计日志）时必须自行拷贝。hook 会直接增加每条消息的处理耗时，
+// 应仅做拷贝与入队等轻量操作；高流量场景可通过 WithAuditSampleRate 按比例采样，或在 hook 内按会话、消息类型自行筛选以控制开销。
+// 若 hook 为 nil 则不修改 Options，并在 Validate 时报错。
+func WithAuditHook(hook func(sessionId string, message []byte)) Option {
+	return func(o *Options) {
+		if hook == nil {
+			o.reject("WithAuditHook", "hook is nil")
+			return
+		}
+		o.AuditHook = hook
+	}
+}
+
+// WithAuditSampleRate 设置 AuditHook 的采样比例，每条入站消息以 rate 的概率被审计，用于限制全量审计的开销。
+//
+// rate 取值 (0, 1]，为 1 时审计全部入站消息（默认），越界会在 Validate 时报错。rate 为 0 时不修改 Options，并在 Validate 时报错，
+// 以免被误认为关闭审计；不需要审计时应不设置 AuditHook。
+func WithAuditSampleRate(rate float64) Option {
+	return func(o *Options) {
+		if rate == 0 {
+			o.reject("WithAuditSampleRate", "rate is 0, leave AuditHook unset to disable auditing")
+			return
+		}
+		o.AuditSampleRate = rate
+	}
+}
+
+// WithTimerJitter 设置所有会话级内部定时器的随机抖动比例。
+//
+// 每个定时器的实际时长会在 [d*(1-fraction), d*(1+fraction)] 内随机取值，避免同时建立的大量会话
+// 在同一时刻触发定时器造成 CPU 与写出尖峰。fraction 取值 [0, 1)，为 0 时不抖动（默认），越界会在 Validate 时报错。
+func WithTimerJitter(fraction float64) Option {
+	return func(o *Options) {
+		o.TimerJitter = fraction
+	}
+}
+
+// WithEventBufferSize 设置 Nexus.Events 返回的生命周期事件通道容量。
+//
+// 通道满时新事件会被丢弃而不会阻塞会话处理；为 0 时使用默认值（1024），负数会在 Validate 时报错。
+func WithEventBufferSize(size int) Option {
+	return func(o *Options) {
+		o.EventBufferSize = size
+	}
+}
+
+// WithStreamChunkSize 设置 SendStream 的分块大小（字节）。
+//
+// 较大的分块可减少写出次数，较小的分块可降低单次写入的内存占用；为 0 时使用默认值（32KiB），负数会在 Validate 时报错。
+func WithStreamChunkSize(size int) Option {
+	return func(o *Options) {
+		o.StreamChunkSize = size
+	}
+}
+
+// WithStreamTimeout 设置单次 SendStream 的最长耗时。
+//
+// SendStream 在整个流期间持有会话的 writeLock 以保证流式数据连续，缓慢的 io.Reader 会阻塞该会话的其他写入；设置后等待 writeLock、
+// 读取与写出共享同一截止时间，到期返回 ErrSendTimeout 并释放 writeLock。底层 Session 实现了 WriteDeadlineSession 时，
+// 写出本身也以该截止时间为写超时，结束后重置为零值。为 0 时不限制（默认），负数会在 Validate 时报错。
+func WithStreamTimeout(timeout time.Duration) Option {
+	return func(o *Options) {
+		o.StreamTimeout = timeout
+	}
+}
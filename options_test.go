@@ -0,0 +1,51 @@
+package nexus_test
+
+import (
+	"strings"
+	"testing"
+
+	nexus "github.com/kercylan98/vivid-nexus"
+)
+
+func TestNilOptionRejected(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		option nexus.Option
+	}{
+		{name: "WithOptions", option: nexus.WithOptions(nil)},
+		{name: "WithSessionReaderProvider", option: nexus.WithSessionReaderProvider(nil)},
+		{name: "WithSessionIdValidator", option: nexus.WithSessionIdValidator(nil)},
+		{name: "WithSessionIdGenerator", option: nexus.WithSessionIdGenerator(nil)},
+		{name: "WithReasonFormatter", option: nexus.WithReasonFormatter(nil)},
+		{name: "WithOutboundFraming", option: nexus.WithOutboundFraming(nil)},
+		{name: "WithSessionErrorHandler", option: nexus.WithSessionErrorHandler(nil)},
+		{name: "WithAck", option: nexus.WithAck(nil, nil)},
+		{name: "WithSlowWrite", option: nexus.WithSlowWrite(0, nil)},
+		{name: "WithSpawnOptions", option: nexus.WithSpawnOptions(nil)},
+		{name: "WithReadBufferProvider", option: nexus.WithReadBufferProvider(nil, nil)},
+		{name: "WithAcceptFunc", option: nexus.WithAcceptFunc(nil)},
+		{name: "WithOnReset", option: nexus.WithOnReset(nil)},
+		{name: "WithOnProvideNil", option: nexus.WithOnProvideNil(nil)},
+		{name: "WithAuditHook", option: nexus.WithAuditHook(nil)},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := nexus.NewOptions(tc.option).Validate()
+			if err == nil || !strings.Contains(err.Error(), tc.name) {
+				t.Fatalf("validate = %v, want error mentioning %s", err, tc.name)
+			}
+			if _, err = nexus.New(provide(&testActor{}), tc.option); err == nil {
+				t.Fatal("new accepted a nil option argument")
+			}
+		})
+	}
+}
+
+func TestWithOptionsKeepsRejected(t *testing.T) {
+	options := nexus.NewOptions(
+		nexus.WithAcceptFunc(nil),
+		nexus.WithOptions(nexus.NewOptions()),
+	)
+	if err := options.Validate(); err == nil {
+		t.Fatal("WithOptions discarded an earlier rejected argument")
+	}
+}
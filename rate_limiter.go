@@ -0,0 +1,54 @@
+package nexus
+
+import "time"
+
+// tokenBucket 是简单的令牌桶限流器，非并发安全，由调用方保证串行访问。
+type tokenBucket struct {
+	rate   float64   // 每秒补充的令牌数
+	burst  float64   // 桶容量
+	tokens float64   // 当前令牌数
+	last   time.Time // 上次补充令牌的时间
+}
+
+// newTokenBucket 创建每秒补充 rate 个令牌、容量为 burst 的令牌桶，初始为满桶；burst 小于等于 0 时取 rate。
+func newTokenBucket(rate, burst int) *tokenBucket {
+	if burst <= 0 {
+		burst = rate
+	}
+	return &tokenBucket{
+		rate:   float64(rate),
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// refill 按 now 与上次补充时间的间隔补充令牌，不超过桶容量。
+func (b *tokenBucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = min(b.burst, b.tokens+elapsed.Seconds()*b.rate)
+		b.last = now
+	}
+}
+
+// allow 尝试消耗 n 个令牌，令牌不足时返回 false 且不消耗。
+func (b *tokenBucket) allow(now time.Time, n float64) bool {
+	b.refill(now)
+	if b.tokens < n {
+		return false
+	}
+	b.tokens -= n
+	return true
+}
+
+// reserve 尝试消耗 n 个令牌（超过桶容量时按桶容量计算，使超大请求在满桶时仍可通过），
+// 成功时返回 0，令牌不足时不消耗并返回令牌补足所需的等待时间。
+func (b *tokenBucket) reserve(now time.Time, n float64) time.Duration {
+	b.refill(now)
+	need := min(n, b.burst)
+	if b.tokens >= need {
+		b.tokens -= need
+		return 0
+	}
+	return time.Duration((need - b.tokens) / b.rate * float64(time.Second))
+}
@@ -0,0 +1,53 @@
+package nexus
+
+// Reason 是 Nexus Kill 会话时使用的关闭原因，用于在日志与指标中可靠地区分会话关闭的来源。
+//
+// 实际传给 Kill 的原因字符串由 Options.ReasonFormatter 格式化，未设置时见 defaultReasonFormatter。
+type Reason string
+
+const (
+	ReasonClose            Reason = "close session"                // Close、CloseWait 等主动关闭
+	ReasonForceClose       Reason = "force close session"          // ForceClose 强制关闭
+	ReasonSendAndClose     Reason = "send and close session"       // SendAndClose 写出最后一条消息后关闭
+	ReasonCloseOwner       Reason = "close owner session"          // CloseOwner 关闭所有者名下的会话
+	ReasonDetach           Reason = "detach session"               // Detach 将会话移出托管
+	ReasonReplaced         Reason = "close existing session"       // 同 ID 的新会话接管，旧会话被替换
+	ReasonCleanup          Reason = "cleanup session"              // Nexus 重启或被 Kill 时清理所有会话
+	ReasonDrain            Reason = "drain session"                // Drain 排空所有会话
+	ReasonLaunchPanic      Reason = "session actor onLaunch panic" // OnConnected 发生 panic
+	ReasonConnectTimeout   Reason = "session connect timeout"      // OnConnected 执行超过 ConnectCloseTimeout
+	ReasonResumeExpired    Reason = "session resume expired"       // EOFPolicyGrace 下宽限期内未被恢复
+	ReasonHandshakeTimeout Reason = "session handshake timeout"    // 超过 HandshakeTimeout 仍未 MarkHandshaked
+	ReasonHandlerTimeout   Reason = "session handler timeout"      // 单条消息处理超过 HandlerTimeout
+	ReasonMailboxFull      Reason = "session mailbox full"         // MailboxFullPolicyClose 下上一条消息处理完成前又读到新消息
+	ReasonReadClosed       Reason = "session read loop closed"     // 读循环正常结束（如对端 EOF）
+	ReasonReadPanic        Reason = "session read loop panic"      // 读循环发生 panic
+	ReasonReadFailed       Reason = "session read failed"          // SessionReader 返回错误，detail 为错误信息
+	ReasonWriteFailed      Reason = "session write failed"         // 禁用读循环时写出失败，detail 为错误信息
+	ReasonMessageError     Reason = "session message error"        // OnMessageE 返回错误，detail 为错误信息
+)
+
+// ReasonFormatter 将关闭原因与可选的细节（如错误信息，可能为空）格式化为传给 Kill 的原因字符串。
+type ReasonFormatter = func(reason Reason, detail string) string
+
+// defaultReasonFormatter 是未设置 Options.ReasonFormatter 时的格式：detail 为空时为 reason，否则为 "reason: detail"。
+func defaultReasonFormatter(reason Reason, detail string) string {
+	if detail == "" {
+		return string(reason)
+	}
+	return string(reason) + ": " + detail
+}
+
+// formatReason 使用 ReasonFormatter 格式化关闭原因，未设置时使用 defaultReasonFormatter。
+func (o *Options) formatReason(reason Reason, detail string) string {
+	if formatter := o.ReasonFormatter; formatter != nil {
+		return formatter(reason, detail)
+	}
+	return defaultReasonFormatter(reason, detail)
+}
+
+// ResetHandler 报告原因的取值，见 WithOnReset。
+const (
+	ResetReasonLaunch   = "launch"   // Nexus Actor 启动（包括重启）时清理遗留会话
+	ResetReasonShutdown = "shutdown" // Nexus Actor 被 Kill 时关闭所有会话
+)
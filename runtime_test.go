@@ -0,0 +1,280 @@
+package nexus_test
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kercylan98/vivid"
+	"github.com/kercylan98/vivid/pkg/log"
+
+	nexus "github.com/kercylan98/vivid-nexus"
+)
+
+// testTimeout 为测试中等待异步状态变化的最长时间。
+const testTimeout = 2 * time.Second
+
+// testSystem 是仅供测试使用的最小 Actor 运行时：每个 Actor 拥有独立的邮箱 goroutine，逐条串行处理消息，
+// 并按 vivid 的生命周期语义投递 OnLaunch、OnKill 与 OnKilled，使测试无需启动完整的 ActorSystem 即可驱动 Nexus。
+type testSystem struct {
+	nextId     atomic.Uint64
+	logger     log.Logger
+	launchGate chan struct{} // 非 nil 时子 Actor 在该通道关闭前不处理任何消息（包括 OnLaunch），用于模拟启动延迟
+	valueRefs  bool          // 为 true 时子 Actor 的引用为不可比较的值类型 valueActorRef，用于验证 Nexus 不以 ActorRef 作为 map 键
+}
+
+// newTestNexus 以 provider 与 options 构造 Nexus 并在 testSystem 中启动，测试结束时 Kill 该 Nexus 并等待其退出。
+func newTestNexus(t testing.TB, provider nexus.SessionActorProvider, options ...nexus.Option) nexus.Nexus {
+	t.Helper()
+	return newTestNexusOn(t, &testSystem{logger: log.NewTextLogger()}, provider, options...)
+}
+
+// newTestNexusOn 同 newTestNexus，但在给定的 system 中启动 Nexus。
+func newTestNexusOn(t testing.TB, system *testSystem, provider nexus.SessionActorProvider, options ...nexus.Option) nexus.Nexus {
+	t.Helper()
+	n, err := nexus.New(provider, options...)
+	if err != nil {
+		t.Fatalf("new nexus: %v", err)
+	}
+	ctx, err := system.spawn(n.(vivid.Actor), nil)
+	if err != nil {
+		t.Fatalf("spawn nexus: %v", err)
+	}
+	t.Cleanup(func() {
+		ctx.kill()
+		<-ctx.doneC
+	})
+
+	waitCtx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	if err = n.WaitStarted(waitCtx); err != nil {
+		t.Fatalf("wait nexus started: %v", err)
+	}
+	return n
+}
+
+// spawn 创建并启动 actor 的邮箱，actor 实现 vivid.PrelaunchActor 时先执行 OnPrelaunch，失败时不启动。
+func (s *testSystem) spawn(actor vivid.Actor, parent *testActorContext) (*testActorContext, error) {
+	ctx := &testActorContext{
+		system:  s,
+		actor:   actor,
+		parent:  parent,
+		notifyC: make(chan struct{}, 1),
+		doneC:   make(chan struct{}),
+	}
+	ctx.ref = &testActorRef{id: s.nextId.Add(1), ctx: ctx}
+	ctx.publicRef = ctx.ref
+	if s.valueRefs && parent != nil {
+		ctx.publicRef = valueActorRef{testActorRef: ctx.ref, path: []string{"test", strconv.FormatUint(ctx.ref.id, 10)}}
+	}
+	if prelaunch, ok := actor.(vivid.PrelaunchActor); ok {
+		if err := prelaunch.OnPrelaunch(nil); err != nil {
+			return nil, err
+		}
+	}
+	ctx.post(&vivid.OnLaunch{})
+	go ctx.run()
+	return ctx, nil
+}
+
+// testActorRef 是 testSystem 中 Actor 的引用，未实现的 vivid.ActorRef 方法被调用时 panic。
+type testActorRef struct {
+	vivid.ActorRef
+	id  uint64
+	ctx *testActorContext
+}
+
+func (r *testActorRef) Equals(ref vivid.ActorRef) bool {
+	other, ok := ref.(testRef)
+	return ok && other.target().id == r.id
+}
+
+func (r *testActorRef) target() *testActorRef {
+	return r
+}
+
+// testRef 由 testSystem 产生的所有 ActorRef 实现，用于取回被引用的 Actor。
+type testRef interface {
+	target() *testActorRef
+}
+
+// valueActorRef 是包含切片字段、不可比较的 ActorRef 值类型，作为 map 键时会 panic。
+type valueActorRef struct {
+	*testActorRef
+	path []string
+}
+
+func (r *testActorRef) String() string {
+	return "test/" + strconv.FormatUint(r.id, 10)
+}
+
+// testActorContext 是 testSystem 中 Actor 的上下文与邮箱，未实现的 vivid.ActorContext 方法被调用时 panic。
+type testActorContext struct {
+	vivid.ActorContext
+	system    *testSystem
+	actor     vivid.Actor
+	ref       *testActorRef
+	publicRef vivid.ActorRef // 交给其他 Actor 的引用，通常即 ref
+	parent    *testActorContext
+	message   any // 当前处理的消息，仅在邮箱 goroutine 中访问
+
+	lock    sync.Mutex
+	queue   []any
+	killed  bool          // 已投递 OnKill，此后不再接收新消息
+	notifyC chan struct{} // 容量为 1，有新消息时通知邮箱 goroutine
+	doneC   chan struct{} // 处理完 OnKill 后关闭
+}
+
+func (c *testActorContext) Message() any {
+	return c.message
+}
+
+func (c *testActorContext) Logger() log.Logger {
+	return c.system.logger
+}
+
+func (c *testActorContext) Ref() vivid.ActorRef {
+	return c.publicRef
+}
+
+func (c *testActorContext) TellSelf(message any) {
+	c.post(message)
+}
+
+func (c *testActorContext) Tell(recipient vivid.ActorRef, message any) {
+	recipient.(testRef).target().ctx.post(message)
+}
+
+func (c *testActorContext) Kill(ref vivid.ActorRef, poison bool, reason ...string) {
+	ref.(testRef).target().ctx.kill()
+}
+
+func (c *testActorContext) ActorOf(actor vivid.Actor, options ...vivid.ActorOption) (vivid.ActorRef, error) {
+	child, err := c.system.spawn(actor, c)
+	if err != nil {
+		return nil, err
+	}
+	return child.publicRef, nil
+}
+
+// post 将 message 追加到邮箱，已投递 OnKill 后丢弃。
+func (c *testActorContext) post(message any) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.killed {
+		return
+	}
+	c.queue = append(c.queue, message)
+	c.notify()
+}
+
+// kill 将 OnKill 插入邮箱最前端（与 vivid 的系统消息优先处理一致），重复调用无操作。
+func (c *testActorContext) kill() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.killed {
+		return
+	}
+	c.killed = true
+	c.queue = append([]any{&vivid.OnKill{}}, c.queue...)
+	c.notify()
+}
+
+func (c *testActorContext) notify() {
+	select {
+	case c.notifyC <- struct{}{}:
+	default:
+	}
+}
+
+// run 逐条处理邮箱中的消息，处理完 OnKill 后退出并向父 Actor 投递 OnKilled。
+func (c *testActorContext) run() {
+	defer close(c.doneC)
+	if gate := c.system.launchGate; gate != nil && c.parent != nil {
+		<-gate
+	}
+	for {
+		c.lock.Lock()
+		if len(c.queue) == 0 {
+			c.lock.Unlock()
+			<-c.notifyC
+			continue
+		}
+		message := c.queue[0]
+		c.queue = c.queue[1:]
+		c.lock.Unlock()
+
+		c.message = message
+		c.actor.OnReceive(c)
+		if _, ok := message.(*vivid.OnKill); ok {
+			if c.parent != nil {
+				c.parent.post(&vivid.OnKilled{Ref: c.publicRef})
+			}
+			return
+		}
+	}
+}
+
+// testActor 是以函数字段实现回调的 SessionActor，未设置的回调为空实现。
+type testActor struct {
+	nexus.BaseSessionActor
+	connected    func(ctx nexus.SessionContext)
+	message      func(ctx nexus.SessionContext, message []byte)
+	disconnected func(ctx nexus.SessionContext)
+}
+
+func (a *testActor) OnConnected(ctx nexus.SessionContext) {
+	if a.connected != nil {
+		a.connected(ctx)
+	}
+}
+
+func (a *testActor) OnMessage(ctx nexus.SessionContext, message []byte) {
+	if a.message != nil {
+		a.message(ctx, message)
+	}
+}
+
+func (a *testActor) OnDisconnected(ctx nexus.SessionContext) {
+	if a.disconnected != nil {
+		a.disconnected(ctx)
+	}
+}
+
+// provide 返回始终提供 actor 的 SessionActorProvider。
+func provide(actor nexus.SessionActor) nexus.SessionActorProvider {
+	return nexus.SessionActorProviderFN(func() (nexus.SessionActor, error) {
+		return actor, nil
+	})
+}
+
+// eventually 在 testTimeout 内轮询 cond 直到其返回 true，超时则以 msg 使测试失败。
+func eventually(t testing.TB, msg string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(testTimeout)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timeout waiting for %s", msg)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// takeover 接管 session 并等待其完成 OnConnected 进入就绪状态。
+func takeover(t testing.TB, n nexus.Nexus, session nexus.Session) {
+	t.Helper()
+	if err := n.TakeoverSession(session); err != nil {
+		t.Fatalf("takeover session %q: %v", session.GetSessionId(), err)
+	}
+	eventually(t, "session "+session.GetSessionId()+" ready", func() bool {
+		for _, snapshot := range n.Snapshot() {
+			if snapshot.SessionId == session.GetSessionId() && snapshot.Ready {
+				return true
+			}
+		}
+		return false
+	})
+}
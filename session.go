@@ -1,6 +1,10 @@
 package nexus
 
-import "io"
+import (
+	"crypto/tls"
+	"io"
+	"time"
+)
 
 // Session 表示底层连接抽象，由接入层（如 TCP、WebSocket）实现。
 type Session interface {
@@ -15,3 +19,54 @@ type MetadataSession interface {
 	// Metadata 返回接入时附加的元数据，无则返回 nil。
 	Metadata() map[string]any
 }
+
+// SharedWriter 是 Session 的可选扩展，由能够安全地直接使用调用方缓冲区写出的传输层实现（如支持 writev 或预编码帧的实现）。
+//
+// 通过 BroadcastShared 发送且会话默认帧类型为 FrameKindDefault 时，Nexus 不再拷贝 message，而是将同一块缓冲区交给所有会话的 WriteShared。
+// 实现方可以在本次写出完成前持有 p，但不得修改 p，也不得在 WriteShared 返回后继续引用 p。
+type SharedWriter interface {
+	Session
+	// WriteShared 写出只读的共享缓冲区 p，返回值语义同 io.Writer.Write。
+	WriteShared(p []byte) (n int, err error)
+}
+
+// WriteDeadlineSession 是支持设置写超时的 Session（如 net.Conn），配置 WithBroadcastSendTimeout 或 WithStreamTimeout 时用于限制写出的耗时。
+//
+// 由于无法读回此前的写超时，Nexus 在每次限时写出前设置写超时、写出后将其重置为零值，
+// 因此配置上述选项时写超时由 Nexus 管理，接入层与业务不应再自行设置；未配置时 Nexus 仅在 ForceClose 启用了出站队列的会话时
+// 将写超时设为当前时间，以中断写循环中进行中的写入，此后连接随即被关闭。
+type WriteDeadlineSession interface {
+	Session
+	// SetWriteDeadline 设置写超时，零值表示不超时。
+	SetWriteDeadline(t time.Time) error
+}
+
+// CodedCloser 是 Session 的可选扩展，由支持在关闭时携带关闭码与原因的传输层（如 WebSocket 关闭帧）实现。
+//
+// 通过 CloseNexus.CloseWithCode 或 SessionContext.CloseWithCode 关闭会话时，Nexus 以 CloseWithCode 代替 Close 关闭底层 Session；
+// 其余关闭路径仍调用 Close。与 Close 相同，Nexus 只会调用一次。
+type CodedCloser interface {
+	Session
+	// CloseWithCode 以关闭码 code 与原因 reason 关闭连接，code 的取值由传输层定义（如 WebSocket 的 1000～4999）。
+	CloseWithCode(code int, reason string) error
+}
+
+// TLSInfoSession 是 Session 的可选扩展，由基于 TLS 的传输层实现，用于向业务提供对端证书、SNI 等握手信息。
+//
+// 业务可通过 SessionContext.TLSConnectionState 在 OnConnected 中读取，实现基于双向 TLS 的鉴权。实现方应在 TLS 握手完成后
+// 再将会话交给 Nexus（如先调用 tls.Conn.Handshake），否则返回的状态可能尚不完整。
+type TLSInfoSession interface {
+	Session
+	// ConnectionState 返回 TLS 连接的状态。
+	ConnectionState() tls.ConnectionState
+}
+
+// ReadLimiter 是 Session 的可选扩展，由能够在协议层限制单条入站消息大小的传输层（如 WebSocket）实现。
+//
+// 配置 WithMaxMessageSize 时 Nexus 会在读循环启动前将上限下发给实现了该接口的 Session，使超限消息在协议层即被拒绝，
+// 而不是完整缓冲后再由 Nexus 判定；业务也可通过 SessionContext.SetReadLimit 为单个会话调整上限。
+type ReadLimiter interface {
+	Session
+	// SetReadLimit 设置单条入站消息的最大字节数。
+	SetReadLimit(limit int64)
+}
@@ -0,0 +1,121 @@
+package nexus
+
+import "time"
+
+// AckEncoder 将序号 seq 编码进出站消息 message，返回实际写出的消息，由 WithAck 配置。
+type AckEncoder = func(seq uint64, message []byte) []byte
+
+// AckExtractor 从入站消息中解析客户端的确认，message 为确认消息时返回其确认的序号与 true，由 WithAck 配置。
+type AckExtractor = func(message []byte) (seq uint64, ok bool)
+
+// registerAck 为 seq 注册确认等待通道，会话已进入关闭流程时返回 ErrSessionClosing。
+func (info *sessionInfo) registerAck(seq uint64) error {
+	info.ackLock.Lock()
+	defer info.ackLock.Unlock()
+
+	if info.closing.Load() {
+		return ErrSessionClosing
+	}
+	if info.acks == nil {
+		info.acks = make(map[uint64]chan struct{})
+	}
+	info.acks[seq] = make(chan struct{})
+	return nil
+}
+
+// resolveAck 标记 seq 已被确认，未注册或已确认的序号无操作。
+func (info *sessionInfo) resolveAck(seq uint64) {
+	info.ackLock.Lock()
+	defer info.ackLock.Unlock()
+
+	if ch, ok := info.acks[seq]; ok {
+		select {
+		case <-ch:
+		default:
+			close(ch)
+		}
+	}
+}
+
+// ackChannel 返回 seq 的确认通道，未注册时返回 false。
+func (info *sessionInfo) ackChannel(seq uint64) (chan struct{}, bool) {
+	info.ackLock.Lock()
+	defer info.ackLock.Unlock()
+
+	ch, ok := info.acks[seq]
+	return ch, ok
+}
+
+// forgetAck 移除 seq 的确认记录。
+func (info *sessionInfo) forgetAck(seq uint64) {
+	info.ackLock.Lock()
+	defer info.ackLock.Unlock()
+
+	delete(info.acks, seq)
+}
+
+// SendWithAck 为 message 分配一个会话内单调递增的序号（与 SessionContext.NextSeq 共用同一计数器），经 AckEncoder 编码后推送到指定 ID 的会话，并返回该序号。
+//
+// 返回的序号应交给 WaitAck 等待客户端确认；确认由 AckExtractor 从入站消息中解析，确认消息会被 Nexus 消费，不会投递给 OnMessage。
+// 未配置 WithAck 时返回 ErrAckNotConfigured，会话不存在时返回 ErrSessionNotFound。每个序号的确认记录会保留到 WaitAck 返回或会话关闭，
+// 因此每次 SendWithAck 都应对应一次 WaitAck。
+func (o *operator) SendWithAck(sessionId string, message []byte) (seq uint64, err error) {
+	if !o.launched.Load() {
+		return 0, ErrNotStarted
+	}
+	encoder := o.actor.options.AckEncoder
+	if encoder == nil || o.actor.options.AckExtractor == nil {
+		return 0, ErrAckNotConfigured
+	}
+
+	o.actor.sessionLock.RLock()
+	info, ok := o.actor.sessions[sessionId]
+	o.actor.sessionLock.RUnlock()
+	if !ok {
+		return 0, ErrSessionNotFound
+	}
+
+	seq = info.nextSeq()
+	if err = info.registerAck(seq); err != nil {
+		return 0, err
+	}
+	if err = info.send(encoder(seq, message)); err != nil {
+		info.forgetAck(seq)
+		return 0, err
+	}
+	return seq, nil
+}
+
+// WaitAck 阻塞等待指定会话对 seq 的确认，最长等待 timeout（小于等于 0 时不限时）。
+//
+// 确认到达（包括在调用 WaitAck 之前到达）时返回 nil；超时返回 ErrAckTimeout；会话关闭返回 ErrSessionClosed；
+// 会话不存在或 seq 并非由 SendWithAck 分配、或已被等待过时返回 ErrSessionNotFound。
+// 确认由目标会话的邮箱线程解析，因此不可在该会话自身的回调中等待，否则只能以超时结束。
+func (o *operator) WaitAck(sessionId string, seq uint64, timeout time.Duration) error {
+	o.actor.sessionLock.RLock()
+	info, ok := o.actor.sessions[sessionId]
+	o.actor.sessionLock.RUnlock()
+	if !ok {
+		return ErrSessionNotFound
+	}
+	ch, ok := info.ackChannel(seq)
+	if !ok {
+		return ErrSessionNotFound
+	}
+	defer info.forgetAck(seq)
+
+	var timeoutC <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		timeoutC = timer.C
+	}
+	select {
+	case <-ch:
+		return nil
+	case <-info.done:
+		return ErrSessionClosed
+	case <-timeoutC:
+		return ErrAckTimeout
+	}
+}
@@ -0,0 +1,17 @@
+package nexus_test
+
+import (
+	"errors"
+	"testing"
+
+	nexus "github.com/kercylan98/vivid-nexus"
+	"github.com/kercylan98/vivid-nexus/nexustest"
+)
+
+func TestSendWithAckNotConfigured(t *testing.T) {
+	n := newTestNexus(t, provide(&testActor{}))
+	takeover(t, n, nexustest.NewMemorySession("ack", nil))
+	if _, err := n.(nexus.DeliveryNexus).SendWithAck("ack", []byte("hello")); !errors.Is(err, nexus.ErrAckNotConfigured) {
+		t.Fatalf("SendWithAck returned %v, want ErrAckNotConfigured", err)
+	}
+}
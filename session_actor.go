@@ -1,9 +1,13 @@
 package nexus
 
 import (
+	"bytes"
 	"errors"
+	"fmt"
 	"io"
+	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/kercylan98/vivid"
 	"github.com/kercylan98/vivid/pkg/log"
@@ -12,14 +16,24 @@ import (
 var (
 	_ vivid.Actor          = (*sessionActor)(nil)
 	_ vivid.PrelaunchActor = (*sessionActor)(nil)
+	_ SessionActor         = BaseSessionActor{}
 )
 
+// errHandlerTimeout 表示业务处理单条消息的耗时超过了 Options.HandlerTimeout。
+var errHandlerTimeout = errors.New("session handler timeout")
+
 // SessionActor 由业务实现的会话逻辑接口，仅需实现连接/断开/收包三个回调。
 //
 // 所有回调均在 sessionActor 的邮箱线程中串行执行，可安全使用 ctx 进行 Send、Close、Tell 等。
 // message 在 OnMessage 中的生命周期仅在本次调用内有效，如需异步或长期持有须拷贝。
+//
+// SessionActor 本身不是 vivid.Actor：会话邮箱由 Nexus 内部的 Actor 驱动，只会调用上述回调及已实现的可选扩展接口。
+// 即使业务类型额外实现了 vivid.Actor 的 OnReceive(vivid.ActorContext)，它也永远不会被调用；
+// 需要处理自定义消息时请实现 ReceiveSessionActor 并通过 SessionContext.TellLater 投递。
 type SessionActor interface {
 	// OnConnected 在会话对应 Actor 启动后、读循环启动前调用，表示连接已就绪。
+	// 读循环在 OnConnected 返回后才启动，因此客户端立即发送的数据不会与 OnConnected 并发处理；
+	// OnConnected 运行在会话邮箱线程中，返回前邮箱被占用；WithConnectCloseTimeout 可在其超时后关闭连接以中断阻塞的读写，但不会中断 OnConnected 本身。
 	OnConnected(ctx SessionContext)
 	// OnDisconnected 在会话即将关闭时调用（Kill 处理中），之后底层 Session 会被 Close。
 	OnDisconnected(ctx SessionContext)
@@ -27,6 +41,79 @@ type SessionActor interface {
 	OnMessage(ctx SessionContext, message []byte)
 }
 
+// BaseSessionActor 为 SessionActor 的三个回调提供空实现，业务嵌入后只需覆盖关心的回调。
+//
+// SessionActor 不要求实现 vivid.Actor 的 OnReceive，因此 BaseSessionActor 也不提供 OnReceive；
+// 需要接收 TellLater 调度的消息时请实现 ReceiveSessionActor。
+//
+// 示例：type EchoActor struct{ nexus.BaseSessionActor }，仅实现 OnMessage 即可。
+type BaseSessionActor struct{}
+
+// OnConnected 为空实现。
+func (BaseSessionActor) OnConnected(ctx SessionContext) {}
+
+// OnDisconnected 为空实现。
+func (BaseSessionActor) OnDisconnected(ctx SessionContext) {}
+
+// OnMessage 为空实现，收到的消息会被丢弃。
+func (BaseSessionActor) OnMessage(ctx SessionContext, message []byte) {}
+
+// MessageErrorSessionActor 是 SessionActor 的可选扩展，允许消息处理以返回 error 的方式关闭会话。
+//
+// 若业务实现了该接口，Nexus 调用 OnMessageE 代替 OnMessage；返回非 nil 时会话将以该错误为原因被关闭，
+// 并交由 Options.SessionErrorHandler 处理，便于与正常关闭区分。未实现时仍调用 OnMessage。
+type MessageErrorSessionActor interface {
+	SessionActor
+	// OnMessageE 处理一条入站消息，返回 error 表示协议违规等需要关闭会话的错误。
+	OnMessageE(ctx SessionContext, message []byte) error
+}
+
+// SwappedSessionActor 是 SessionActor 的可选扩展，用于感知 SessionContext.SwapActor 引起的替换。
+//
+// 通过 SwapActor 切换到实现了该接口的 Actor 时，仅调用其 OnSwapped，不再调用原 Actor 的 OnDisconnected
+// 与新 Actor 的 OnConnected，适用于认证前后等仅切换协议阶段、连接本身并未变化的场景。
+type SwappedSessionActor interface {
+	SessionActor
+	// OnSwapped 在替换完成后调用，previous 为被替换的原 SessionActor。
+	OnSwapped(ctx SessionContext, previous SessionActor)
+}
+
+// BatchSessionActor 是 SessionActor 的可选扩展，配合 WithMessageBatching 以批为单位接收入站消息。
+//
+// 启用批量投递且业务实现了该接口时，Nexus 调用 OnMessages 代替逐条的 OnMessage；未启用批量投递时不会被调用。
+type BatchSessionActor interface {
+	SessionActor
+	// OnMessages 处理一批入站消息，messages 按读取顺序排列且不为空，其中已被 Ask 等待者消费的消息不会出现。
+	OnMessages(ctx SessionContext, messages [][]byte)
+}
+
+// ReceiveSessionActor 是 SessionActor 的可选扩展，用于接收 SessionContext.TellLater 调度的消息。
+//
+// 调度的消息到期后投递到本会话的邮箱，并在邮箱线程中以 OnReceive 交给业务；未实现该接口时到期的消息会被丢弃并记录警告日志。
+type ReceiveSessionActor interface {
+	SessionActor
+	// OnReceive 处理一条由 TellLater 调度、已到期的消息。
+	OnReceive(ctx SessionContext, message any)
+}
+
+// scheduledMessage 包装 TellLater 调度的业务消息，与 readLoop 投递的 []byte 区分。
+type scheduledMessage struct {
+	message any
+}
+
+// messageBatch 是批量投递时 readLoop 投递到邮箱的一批入站消息。
+type messageBatch [][]byte
+
+// ReplacedSessionActor 是 SessionActor 的可选扩展，用于区分会话被同 ID 新会话替换与普通断开。
+//
+// 会话因同 ID 的新会话接管而被关闭时，若业务实现了该接口则调用 OnReplaced 代替 OnDisconnected，
+// 适用于多端登录时提示旧设备"已在别处登录"等场景；未实现时仍调用 OnDisconnected，可通过 SessionContext.IsReplaced 判断。
+type ReplacedSessionActor interface {
+	SessionActor
+	// OnReplaced 在会话因被替换而即将关闭时调用，语义与 OnDisconnected 相同（之后底层 Session 会被 Close）。
+	OnReplaced(ctx SessionContext)
+}
+
 // SessionActorProvider 为每个新会话提供一个 SessionActor 实例。
 //
 // Nexus 在创建 sessionActor 时调用 Provide()；返回 nil 或 error 则会话不启动。
@@ -48,12 +135,18 @@ func (fn SessionActorProviderFN) Provide() (SessionActor, error) {
 
 // newSessionActor 构造与给定 sessionInfo 绑定的 sessionActor，Prelaunch 前不会启动读循环。
 func newSessionActor(sessionInfo *sessionInfo, provider SessionActorProvider, options Options) *sessionActor {
-	return &sessionActor{
+	a := &sessionActor{
 		context:  &sessionContext{sessionInfo: sessionInfo},
 		options:  options,
 		provider: provider,
-		messageC: make(chan struct{}),
+		messageC: make(chan struct{}, 1),
+	}
+	if options.InboundDedupWindow > 0 {
+		a.dedup = newInboundDedup(options.InboundDedupWindow, options.InboundDedupKey)
 	}
+	a.context.sessionActor = a
+	sessionInfo.context = a.context
+	return a
 }
 
 // sessionActor 将单个 Session 封装为 vivid Actor，负责 Prelaunch/Launch/Kill 与独立读循环，
@@ -62,14 +155,41 @@ type sessionActor struct {
 	context              *sessionContext // 组合 Session + ActorContext，传给业务
 	options              Options         // 含 SessionReaderProvider 等配置
 	provider             SessionActorProvider
-	reader               SessionReader // 由 SessionReaderProvider 按 Session 提供
-	externalSessionActor SessionActor  // 业务实现的回调对象
-	closed               atomic.Bool   // 仅 CAS/Load，保证 readLoop 与 onKill 间可见性
-	messageC             chan struct{} // 背压：onMessage 处理完后发送，readLoop 接收后继续读
+	reader               SessionReader            // 由 SessionReaderProvider 按 Session 提供，启动读循环后由 readerLock 保护
+	readerLock           sync.RWMutex             // 保护 reader 与 retiredReaders；每次 Read 期间持有读锁，使 SwapReader 只发生在两次读取之间
+	retiredReaders       []SessionReader          // 被 SwapReader 替换下来的 Reader，会话结束后与当前 Reader 一并 Close
+	externalSessionActor SessionActor             // 业务实现的回调对象
+	closed               atomic.Bool              // 仅 CAS/Load，保证 readLoop 与 onKill 间可见性
+	messageC             chan struct{}            // 背压：onMessage 处理完后发送，readLoop 接收后继续读；容量为 1，避免超时后迟到的信号阻塞邮箱
+	handshakeTimer       *time.Timer              // 握手超时定时器，未配置 HandshakeTimeout 时为 nil，仅在邮箱线程中访问
+	graceTimer           *time.Timer              // EOF 宽限期定时器，仅在 EOFPolicyGrace 下进入宽限期时创建，仅在邮箱线程中访问
+	dedup                *inboundDedup            // 入站消息去重，未启用 InboundDedupWindow 时为 nil，仅在邮箱线程中访问
+	logger               log.Logger               // 绑定 session_id 字段的会话级日志，在 onLaunch 中创建，此后只读
+	readers              sync.WaitGroup           // 正在读取底层 Session 的 goroutine（readLoop 及批量投递的预读 goroutine）
+	timerLock            sync.Mutex               // 保护 timers
+	timers               map[*time.Timer]struct{} // TellLater 创建且尚未到期的定时器，关闭时置为 nil 并全部停止
+	pauseLock            sync.Mutex               // 保护 resumeC
+	resumeC              chan struct{}            // 暂停读取时非 nil，ResumeReading 关闭后置为 nil
 }
 
 // OnPrelaunch 在 Actor 真正启动前执行：拉取 SessionActor 与 SessionReader，任一失败则会话不启动。
+//
+// 会话不启动时不会经过 onKill，因此任一失败都会在此处直接关闭底层 Session，避免连接泄漏。
 func (a *sessionActor) OnPrelaunch(ctx vivid.PrelaunchContext) (err error) {
+	var provideNil bool
+	defer func() {
+		if err != nil {
+			a.context.sessionInfo.closeQueue()
+			_ = a.context.sessionInfo.closeSession()
+			if handler := a.options.ProvideNilHandler; provideNil && handler != nil {
+				handler(a.context.session())
+			}
+			a.context.sessionInfo.closeDone()
+			a.releaseActor()
+			a.releaseReader()
+		}
+	}()
+
 	if a.closed.Load() {
 		return errors.New("session already closed")
 	}
@@ -79,17 +199,24 @@ func (a *sessionActor) OnPrelaunch(ctx vivid.PrelaunchContext) (err error) {
 		return err
 	}
 	if externalSessionActor == nil {
+		provideNil = true
 		return errors.New("session actor provider provide nil session actor")
 	}
 	a.externalSessionActor = externalSessionActor
+	if a.options.DisableReadLoop {
+		return nil
+	}
 
-	a.reader, err = a.options.SessionReaderProvider.Provide(a.context.Session)
+	a.reader, err = a.options.SessionReaderProvider.Provide(a.context.session())
 	if err != nil {
 		return err
 	}
 	if a.reader == nil {
 		return errors.New("session reader provider provide nil session reader")
 	}
+	if !a.options.bindReadBuffer(a.reader) {
+		a.context.operator.actor.warnReadBufferUnused()
+	}
 	return err
 }
 
@@ -102,6 +229,14 @@ func (a *sessionActor) OnReceive(ctx vivid.ActorContext) {
 		a.onKill(ctx, msg)
 	case []byte:
 		a.onMessage(ctx, msg)
+	case messageBatch:
+		a.onMessages(ctx, msg)
+	case scheduledMessage:
+		a.onScheduled(msg)
+	case sessionSuspend:
+		a.onSuspend(ctx)
+	case *sessionResume:
+		a.onResume(ctx, msg)
 	}
 }
 
@@ -109,79 +244,608 @@ func (a *sessionActor) OnReceive(ctx vivid.ActorContext) {
 func (a *sessionActor) onLaunch(ctx vivid.ActorContext) {
 	// 注入 context
 	a.context.ActorContext = ctx
+	a.logger = ctx.Logger().With(log.String("session_id", a.context.GetSessionId()))
 
 	defer func() {
 		// 如果在 OnConnected 或 readLoop 中发生 panic，则杀死自己，避免异常连接进入
 		if err := recover(); err != nil {
-			ctx.Logger().Error("session actor onLaunch panic", log.String("id", a.context.GetSessionId()), log.Any("err", err))
-			ctx.Kill(ctx.Ref(), false, "session actor onLaunch panic")
+			a.context.Logger().Error(string(ReasonLaunchPanic), log.Any("err", err))
+			ctx.Kill(ctx.Ref(), false, a.options.formatReason(ReasonLaunchPanic, ""))
 		}
 	}()
 
-	a.externalSessionActor.OnConnected(a.context)
+	a.armHandshakeTimer(ctx)
+	a.bindSession()
+
+	if _, ok := a.externalSessionActor.(vivid.Actor); ok {
+		if _, ok = a.externalSessionActor.(ReceiveSessionActor); !ok {
+			a.context.Logger().Warn("session actor implements vivid.Actor, its OnReceive will never be called, implement ReceiveSessionActor instead")
+		}
+	}
+	if !a.connect(ctx) {
+		return
+	}
+	if !a.closed.Load() {
+		a.context.sessionInfo.ready.Store(true)
+	}
+	if a.options.DisableReadLoop {
+		return
+	}
+	a.readers.Add(1)
 	go a.readLoop(ctx)
 }
 
-// onKill 幂等关闭会话：仅首次 CAS 成功时执行 defer（close messageC、Close Session、OnDisconnected）。
+// onKill 幂等关闭会话：仅首次 CAS 成功时执行 defer（close messageC、写出剩余缓冲、Close Session、OnDisconnected）。
 func (a *sessionActor) onKill(ctx vivid.ActorContext, msg *vivid.OnKill) {
 	if !a.closed.CompareAndSwap(false, true) {
 		return
 	}
+	a.context.sessionInfo.ready.Store(false)
+	a.context.sessionInfo.closeWaiters()
+	if a.handshakeTimer != nil {
+		a.handshakeTimer.Stop()
+	}
+	if a.graceTimer != nil {
+		a.graceTimer.Stop()
+	}
+	a.stopTimers()
+	if !a.context.sessionInfo.beginGracefulClose() {
+		a.forceKill(ctx, msg)
+		return
+	}
+	// 关闭顺序：先关闭出站队列并等待写循环写完进行中的消息，再在 writeLock 下写出剩余缓冲与队列中尚未写出的消息，
+	// 然后标记 closing，最后关闭底层 Session。因此在此之前已完成的同步写入与已入队的消息（包括 OnDisconnected 中发送的）
+	// 均按序写出，此后的写入一律返回 ErrSessionClosing。
 	defer func() {
 		close(a.messageC)
+		var pending []*sendItem
+		if queue := a.context.sessionInfo.queue; queue != nil {
+			pending = queue.closeAndTake()
+			<-a.context.sessionInfo.writerDone
+		}
 		a.context.sessionInfo.writeLock.Lock()
 		defer a.context.sessionInfo.writeLock.Unlock()
-		if err := a.context.Session.Close(); err != nil {
-			ctx.Logger().Error("session close failed", log.String("id", a.context.GetSessionId()), log.Any("reason", msg), log.Any("err", err))
+		if err := a.context.sessionInfo.flush(); err != nil {
+			a.context.Logger().Error("session flush failed", log.Any("err", err))
+		}
+		a.context.sessionInfo.writePending(pending)
+		a.context.sessionInfo.closing.Store(true)
+		if !a.context.sessionInfo.detached.Load() {
+			if err := a.context.sessionInfo.closeSession(); err != nil {
+				a.context.Logger().Error("session close failed", log.Any("reason", msg), log.Any("err", err))
+			}
 		}
+		a.context.sessionInfo.closeDone()
+		a.releaseActor()
+		a.releaseReader()
 	}()
 
+	if actor, ok := a.externalSessionActor.(ReplacedSessionActor); ok && a.context.sessionInfo.replaced.Load() {
+		actor.OnReplaced(a.context)
+		return
+	}
 	a.externalSessionActor.OnDisconnected(a.context)
 }
 
-// readLoop 在独立 goroutine 中循环读取；每次读到的数据 TellSelf 后通过 <-messageC 等待处理完成再读下一条。
+// forceKill 是 ForceClose 触发的关闭路径：不调用 OnDisconnected、不写出剩余缓冲，也不等待 writeLock，以便尽快释放已知失效的连接。
+//
+// 关闭顺序：先标记 closing 并关闭出站队列，未写出的消息以 ErrSessionClosed 结束，写循环不再取出新消息；
+// 底层 Session 实现 WriteDeadlineSession 时将写超时设为当前时间以中断写循环中进行中的写入，待写循环退出后再关闭底层 Session，
+// 否则先关闭底层 Session 以唤醒阻塞的写入，再等待写循环退出。两种情况下写循环均在 done 关闭前退出。
+func (a *sessionActor) forceKill(ctx vivid.ActorContext, msg *vivid.OnKill) {
+	info := a.context.sessionInfo
+	info.closing.Store(true)
+	close(a.messageC)
+	info.closeQueue()
+	if info.writerDone != nil {
+		if session, ok := unwrapSession[WriteDeadlineSession](info.session()); ok {
+			_ = session.SetWriteDeadline(time.Now())
+			<-info.writerDone
+		}
+	}
+	if err := info.closeSession(); err != nil {
+		a.context.Logger().Error("session close failed", log.Any("reason", msg), log.Any("err", err))
+	}
+	if info.writerDone != nil {
+		<-info.writerDone
+	}
+	info.closeDone()
+	a.releaseActor()
+	a.releaseReader()
+}
+
+// releaseActor 在 provider 实现了 ReleasableSessionActorProvider 时回收当前的 externalSessionActor，此后不再使用该实例。
+func (a *sessionActor) releaseActor() {
+	actor := a.externalSessionActor
+	if actor == nil {
+		return
+	}
+	if provider, ok := a.provider.(ReleasableSessionActorProvider); ok {
+		provider.Release(actor)
+	}
+}
+
+// releaseReader 待所有读取 goroutine 退出后，对实现了 io.Closer 的当前及被替换的 SessionReader 调用 Close，以便归还读缓冲区等资源。
+//
+// 在邮箱线程中调用，此时不会再有消息处理使用 Read 返回的数据；读取 goroutine 可能仍阻塞在 Read 中，因此在后台等待其退出。
+func (a *sessionActor) releaseReader() {
+	go func() {
+		a.readers.Wait()
+		a.readerLock.Lock()
+		readers := append(a.retiredReaders, a.reader)
+		a.retiredReaders = nil
+		a.readerLock.Unlock()
+		for _, reader := range readers {
+			closer, ok := reader.(io.Closer)
+			if !ok {
+				continue
+			}
+			if err := closer.Close(); err != nil {
+				a.context.Logger().Warn("session reader close failed", log.Any("err", err))
+			}
+		}
+	}()
+}
+
+// bindSession 为当前底层 Session 下发 MaxMessageSize 读上限并注册控制帧处理函数，在启动及恢复会话时调用。
+func (a *sessionActor) bindSession() {
+	if limit := a.options.MaxMessageSize; limit > 0 {
+		a.context.SetReadLimit(limit)
+	}
+	if session, ok := unwrapSession[ControlSession](a.context.session()); ok {
+		info := a.context.sessionInfo
+		session.SetControlHandler(func(kind ControlKind, payload []byte) {
+			info.handleControl(session, kind, payload)
+		})
+	}
+}
+
+// connect 调用 OnConnected；配置了 ConnectCloseTimeout 时，超时后关闭底层 Session 以中断其中阻塞的读写，
+// 并在 OnConnected 返回后 Kill 本会话、返回 false，此时不再启动读循环。OnConnected 返回前邮箱始终被占用。
+func (a *sessionActor) connect(ctx vivid.ActorContext) bool {
+	timeout := a.options.ConnectCloseTimeout
+	if timeout <= 0 {
+		a.externalSessionActor.OnConnected(a.context)
+		return true
+	}
+
+	info := a.context.sessionInfo
+	timer := time.AfterFunc(timeout, func() {
+		_ = info.closeSession()
+	})
+	a.externalSessionActor.OnConnected(a.context)
+	if timer.Stop() {
+		return true
+	}
+	a.context.Logger().Warn(string(ReasonConnectTimeout), log.Any("timeout", timeout))
+	ctx.Kill(ctx.Ref(), false, a.options.formatReason(ReasonConnectTimeout, ""))
+	return false
+}
+
+// armHandshakeTimer 在配置了 HandshakeTimeout 时启动握手超时定时器，到期仍未 MarkHandshaked 则 Kill 本会话。
+func (a *sessionActor) armHandshakeTimer(ctx vivid.ActorContext) {
+	timeout := a.options.HandshakeTimeout
+	if timeout <= 0 {
+		return
+	}
+	a.handshakeTimer = time.AfterFunc(jitter(timeout, a.options.TimerJitter), func() {
+		if a.context.sessionInfo.handshaked.Load() || a.closed.Load() {
+			return
+		}
+		a.context.Logger().Warn(string(ReasonHandshakeTimeout), log.Any("timeout", timeout))
+		ctx.Kill(ctx.Ref(), false, a.options.formatReason(ReasonHandshakeTimeout, ""))
+	})
+}
+
+// readLoop 在独立 goroutine 中循环读取；每次读到的数据 TellSelf 后通过 <-messageC 等待处理完成再读下一条，
+// 配置了非 MailboxFullPolicyBlock 的 MailboxFullPolicy 时改由 readOffering 按策略处理。
 // 严禁在此 goroutine 内使用 ctx 做 ActorSpawn 等并发非安全操作；异常或 EOF 时 defer 会 Kill 本 Actor。
 func (a *sessionActor) readLoop(ctx vivid.ActorContext) {
 	var err error
-	var n int
 	var data []byte
 
+	defer a.readers.Done()
 	defer func() {
-		var reason = "session read loop closed"
+		var reason, detail = ReasonReadClosed, ""
 		if err := recover(); err != nil {
-			reason = "session read loop panic"
-			ctx.Logger().Error(reason, log.Any("err", err))
+			reason = ReasonReadPanic
+			a.context.Logger().Error(string(reason), log.Any("err", err))
 		}
 
-		if err != nil && !errors.Is(err, io.EOF) {
-			reason = "session read failed, err: " + err.Error()
-			ctx.Logger().Error(reason, log.String("id", a.context.GetSessionId()))
+		if a.context.sessionInfo.detached.Load() {
+			// Detach 通过读超时中断读取，此时的读错误是预期内的
+			reason = ReasonDetach
+		} else if errors.Is(err, errHandlerTimeout) {
+			reason = ReasonHandlerTimeout
+			a.context.Logger().Error(string(reason), log.Any("timeout", a.options.HandlerTimeout))
+		} else if errors.Is(err, errMailboxFull) {
+			reason = ReasonMailboxFull
+			a.context.Logger().Warn(string(reason))
+		} else if err != nil && !errors.Is(err, io.EOF) {
+			reason, detail = ReasonReadFailed, err.Error()
+			a.context.Logger().Error(string(reason), log.Any("err", err))
 		}
 
-		if !a.closed.Load() {
-			ctx.Kill(ctx.Ref(), false, reason)
+		if a.closed.Load() {
+			return
 		}
+		if reason == ReasonReadClosed && errors.Is(err, io.EOF) && a.options.EOFPolicy == EOFPolicyGrace {
+			// 对端正常断开，保留会话等待同 ID 的新连接恢复
+			ctx.TellSelf(sessionSuspend{})
+			return
+		}
+		ctx.Kill(ctx.Ref(), false, a.options.formatReason(reason, detail))
 	}()
 
-	for !a.closed.Load() {
-		n, data, err = a.reader.Read()
-		if err != nil {
+	if a.options.MessageBatchSize > 0 {
+		err = a.readBatches(ctx)
+		return
+	}
+	if a.options.MailboxFullPolicy != MailboxFullPolicyBlock {
+		err = a.readOffering(ctx)
+		return
+	}
+
+	for {
+		if data, err = a.readFrame(); err != nil {
 			return
 		}
-		if dataLen := len(data); n != dataLen {
+		a.context.sessionInfo.handlerBusy.Store(true)
+		ctx.TellSelf(data)
+		if err = a.awaitMessage(); err != nil {
 			return
 		}
-		ctx.TellSelf(data)
-		<-a.messageC
 	}
 }
 
-// onMessage 处理邮箱中的 []byte：业务处理完成后若未关闭则向 messageC 发送信号，以解除 readLoop 的背压等待。
-func (a *sessionActor) onMessage(_ vivid.ActorContext, message []byte) {
-	defer func() {
-		if !a.closed.Load() {
-			a.messageC <- struct{}{}
+// readFrame 从 SessionReader 读取下一条完整消息，暂无数据时按 readRetryInterval 重试；
+// 会话已关闭或读取长度与数据不一致时返回 io.EOF，使读循环按正常关闭处理。
+func (a *sessionActor) readFrame() ([]byte, error) {
+	if !a.awaitResume() {
+		return nil, io.EOF
+	}
+	for !a.closed.Load() {
+		n, data, err := a.readOnce()
+		if n > 0 {
+			a.context.sessionInfo.bytesIn.Add(uint64(n))
+			a.context.sessionInfo.touch()
+		}
+		if errors.Is(err, ErrNoDataYet) || (err == nil && n == 0) {
+			time.Sleep(a.readRetryInterval())
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if n != len(data) {
+			return nil, io.EOF
+		}
+		if limit := a.options.MaxMessageSize; limit > 0 && int64(n) > limit {
+			return nil, ErrFrameTooLarge
+		}
+		return data, nil
+	}
+	return nil, io.EOF
+}
+
+// readOnce 在 readerLock 下调用当前 Reader 的 Read，保证 SwapReader 不会在读取过程中替换 Reader。
+func (a *sessionActor) readOnce() (n int, data []byte, err error) {
+	a.readerLock.RLock()
+	defer a.readerLock.RUnlock()
+	return a.reader.Read()
+}
+
+// swapReader 以 reader 替换读循环使用的 Reader，等待进行中的 Read 返回后生效；被替换的 Reader 在会话结束后 Close。
+func (a *sessionActor) swapReader(reader SessionReader) error {
+	if reader == nil {
+		return errors.New("session reader is nil")
+	}
+	if a.options.DisableReadLoop || a.options.MessageBatchSize > 0 {
+		return ErrReaderSwapUnsupported
+	}
+	if a.closed.Load() {
+		return ErrSessionClosed
+	}
+
+	a.readerLock.Lock()
+	defer a.readerLock.Unlock()
+	if a.reader == reader {
+		return nil
+	}
+	a.retiredReaders = append(a.retiredReaders, a.reader)
+	a.reader = reader
+	return nil
+}
+
+// tellLater 在 delay 后向本会话的邮箱投递 message，会话先于到期关闭时自动取消。
+func (a *sessionActor) tellLater(delay time.Duration, message any) {
+	a.timerLock.Lock()
+	defer a.timerLock.Unlock()
+	if a.closed.Load() {
+		return
+	}
+	if a.timers == nil {
+		a.timers = make(map[*time.Timer]struct{})
+	}
+
+	var timer *time.Timer
+	timer = time.AfterFunc(delay, func() {
+		a.timerLock.Lock()
+		_, pending := a.timers[timer]
+		delete(a.timers, timer)
+		a.timerLock.Unlock()
+		if pending && !a.closed.Load() {
+			a.context.ActorContext.TellSelf(scheduledMessage{message: message})
+		}
+	})
+	a.timers[timer] = struct{}{}
+}
+
+// stopTimers 停止所有尚未到期的 TellLater 定时器，此后到期的回调不再投递消息。
+func (a *sessionActor) stopTimers() {
+	a.timerLock.Lock()
+	defer a.timerLock.Unlock()
+	for timer := range a.timers {
+		timer.Stop()
+	}
+	a.timers = nil
+}
+
+// onScheduled 将到期的 TellLater 消息交给实现了 ReceiveSessionActor 的业务，会话已关闭时丢弃。
+func (a *sessionActor) onScheduled(msg scheduledMessage) {
+	if a.closed.Load() {
+		return
+	}
+	if actor, ok := a.externalSessionActor.(ReceiveSessionActor); ok {
+		actor.OnReceive(a.context, msg.message)
+		return
+	}
+	a.context.Logger().Warn("scheduled message dropped, session actor does not implement ReceiveSessionActor", log.Any("message", msg.message))
+}
+
+// pauseReading 暂停读循环，已暂停时无操作。
+func (a *sessionActor) pauseReading() {
+	a.pauseLock.Lock()
+	defer a.pauseLock.Unlock()
+	if a.resumeC == nil {
+		a.resumeC = make(chan struct{})
+	}
+}
+
+// resumeReading 恢复被暂停的读循环，未暂停时无操作。
+func (a *sessionActor) resumeReading() {
+	a.pauseLock.Lock()
+	defer a.pauseLock.Unlock()
+	if a.resumeC != nil {
+		close(a.resumeC)
+		a.resumeC = nil
+	}
+}
+
+// awaitResume 在读循环被暂停时阻塞直到恢复或会话终止，会话终止时返回 false。
+func (a *sessionActor) awaitResume() bool {
+	a.pauseLock.Lock()
+	resumeC := a.resumeC
+	a.pauseLock.Unlock()
+	if resumeC == nil {
+		return true
+	}
+	select {
+	case <-resumeC:
+		return true
+	case <-a.context.sessionInfo.done:
+		return false
+	}
+}
+
+// readBatches 是启用 MessageBatchSize 时的读循环：独立 goroutine 预读消息，当前 goroutine 凑批后投递并等待处理完成。
+func (a *sessionActor) readBatches(ctx vivid.ActorContext) error {
+	maxBatch, maxWait := a.options.MessageBatchSize, a.options.MessageBatchWait
+	frames := make(chan []byte, maxBatch)
+	errC := make(chan error, 1)
+	stop := make(chan struct{})
+	defer close(stop)
+
+	a.readers.Add(1)
+	go func() {
+		defer a.readers.Done()
+		defer close(frames)
+		defer func() {
+			if err := recover(); err != nil {
+				errC <- fmt.Errorf("session read panic: %v", err)
+			}
+		}()
+		for {
+			data, err := a.readFrame()
+			if err != nil {
+				errC <- err
+				return
+			}
+			select {
+			case frames <- bytes.Clone(data):
+			case <-stop:
+				return
+			}
 		}
 	}()
+
+	for !a.closed.Load() {
+		first, ok := <-frames
+		if !ok {
+			return <-errC
+		}
+
+		batch := messageBatch{first}
+		var timer *time.Timer
+		var timeout <-chan time.Time
+		if maxWait > 0 {
+			timer = time.NewTimer(maxWait)
+			timeout = timer.C
+		}
+	collect:
+		for len(batch) < maxBatch {
+			if timeout == nil {
+				select {
+				case frame, ok := <-frames:
+					if !ok {
+						break collect
+					}
+					batch = append(batch, frame)
+				default:
+					break collect
+				}
+				continue
+			}
+			select {
+			case frame, ok := <-frames:
+				if !ok {
+					break collect
+				}
+				batch = append(batch, frame)
+			case <-timeout:
+				break collect
+			}
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+
+		a.context.sessionInfo.handlerBusy.Store(true)
+		ctx.TellSelf(batch)
+		if err := a.awaitMessage(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readRetryInterval 返回 SessionReader 暂无数据时的重试间隔。
+func (a *sessionActor) readRetryInterval() time.Duration {
+	interval := a.options.ReadRetryInterval
+	if interval <= 0 {
+		interval = defaultReadRetryInterval
+	}
+	return jitter(interval, a.options.TimerJitter)
+}
+
+// awaitMessage 等待 onMessage 处理完成的背压信号；配置了 HandlerTimeout 时最长等待该时长，超时返回 errHandlerTimeout。
+//
+// 超时后 readLoop 退出，此后迟到的 onMessage 信号会落入 messageC 的缓冲区，不会阻塞邮箱线程。
+func (a *sessionActor) awaitMessage() error {
+	timeout := a.options.HandlerTimeout
+	if timeout <= 0 {
+		<-a.messageC
+		return nil
+	}
+
+	timer := time.NewTimer(jitter(timeout, a.options.TimerJitter))
+	defer timer.Stop()
+	select {
+	case <-a.messageC:
+		return nil
+	case <-timer.C:
+		return errHandlerTimeout
+	}
+}
+
+// onMessage 处理邮箱中的 []byte：优先交由 Ask 等待者匹配，未匹配时交给业务处理；
+// 处理完成后若未关闭则向 messageC 发送信号，以解除 readLoop 的背压等待。
+func (a *sessionActor) onMessage(ctx vivid.ActorContext, message []byte) {
+	defer a.release()
+	a.audit(message)
+	if a.isDuplicate(message) || a.resolveAck(message) || a.context.sessionInfo.resolveWaiter(message) {
+		return
+	}
+	a.handleMessage(ctx, message)
+}
+
+// onMessages 处理批量投递的一批消息：先逐条交由 Ask 等待者匹配，剩余消息交给 BatchSessionActor.OnMessages，
+// 未实现时逐条交给业务处理；整批处理完成后才解除 readLoop 的背压等待。
+func (a *sessionActor) onMessages(ctx vivid.ActorContext, batch messageBatch) {
+	defer a.release()
+	messages := make([][]byte, 0, len(batch))
+	for _, message := range batch {
+		a.audit(message)
+		if !a.isDuplicate(message) && !a.resolveAck(message) && !a.context.sessionInfo.resolveWaiter(message) {
+			messages = append(messages, message)
+		}
+	}
+	if len(messages) == 0 || a.closed.Load() {
+		return
+	}
+	if actor, ok := a.externalSessionActor.(BatchSessionActor); ok {
+		actor.OnMessages(a.context, messages)
+		return
+	}
+	for _, message := range messages {
+		if a.closed.Load() {
+			return
+		}
+		a.handleMessage(ctx, message)
+	}
+}
+
+// isDuplicate 报告 message 是否为去重窗口内的重复消息，未启用去重时始终返回 false。
+func (a *sessionActor) isDuplicate(message []byte) bool {
+	return a.dedup != nil && a.dedup.duplicate(message, time.Now())
+}
+
+// resolveAck 在配置了 AckExtractor 且 message 为确认消息时标记对应序号已确认并返回 true，此时该消息不再投递给业务。
+func (a *sessionActor) resolveAck(message []byte) bool {
+	extractor := a.options.AckExtractor
+	if extractor == nil {
+		return false
+	}
+	seq, ok := extractor(message)
+	if ok {
+		a.context.sessionInfo.resolveAck(seq)
+	}
+	return ok
+}
+
+// release 在消息处理完成后向 messageC 发送信号，以解除 readLoop 的背压等待；会话已关闭时不发送。
+func (a *sessionActor) release() {
+	a.context.sessionInfo.handlerBusy.Store(false)
+	if !a.closed.Load() {
+		a.messageC <- struct{}{}
+	}
+}
+
+// handleMessage 将单条入站消息交给业务：实现 MessageErrorSessionActor 时调用 OnMessageE 并在出错时关闭会话，否则调用 OnMessage。
+// 会话已关闭（OnDisconnected 已调用，SessionActor 可能已被回收）时丢弃迟到的消息。
+func (a *sessionActor) handleMessage(ctx vivid.ActorContext, message []byte) {
+	if a.closed.Load() {
+		return
+	}
+	if actor, ok := a.externalSessionActor.(MessageErrorSessionActor); ok {
+		if err := actor.OnMessageE(a.context, message); err != nil {
+			a.context.Logger().Warn(string(ReasonMessageError), log.Any("err", err))
+			ctx.Kill(ctx.Ref(), false, a.options.formatReason(ReasonMessageError, err.Error()))
+			a.context.operator.actor.reportSessionError(a.context.session(), err)
+		}
+		return
+	}
 	a.externalSessionActor.OnMessage(a.context, message)
 }
+
+// swapActor 将 externalSessionActor 替换为 newActor，必须在邮箱线程中调用。
+//
+// 若 newActor 实现了 SwappedSessionActor 则仅调用其 OnSwapped；否则依次调用原 Actor 的 OnDisconnected 与 newActor 的 OnConnected。
+func (a *sessionActor) swapActor(newActor SessionActor) error {
+	if newActor == nil {
+		return errors.New("swap session actor is nil")
+	}
+	if a.closed.Load() {
+		return ErrSessionClosed
+	}
+
+	previous := a.externalSessionActor
+	a.externalSessionActor = newActor
+	if swapped, ok := newActor.(SwappedSessionActor); ok {
+		swapped.OnSwapped(a.context, previous)
+		return nil
+	}
+	previous.OnDisconnected(a.context)
+	newActor.OnConnected(a.context)
+	return nil
+}
@@ -0,0 +1,48 @@
+package nexus
+
+import "sync"
+
+// ResettableSessionActor 是 SessionActor 的可选扩展，表示实例可在重置后被其他会话复用，配合 PooledProvider 使用。
+type ResettableSessionActor interface {
+	SessionActor
+	// Reset 清除实例上与上一个会话相关的全部状态，使其等同于新创建的实例。
+	Reset()
+}
+
+// ReleasableSessionActorProvider 是 SessionActorProvider 的可选扩展，用于回收不再使用的 SessionActor。
+//
+// 会话关闭且所有回调执行完毕后，Nexus 以该会话当前的 SessionActor 调用 Release；会话未能启动时同样会回收已提供的实例。
+type ReleasableSessionActorProvider interface {
+	SessionActorProvider
+	// Release 回收 actor，调用后 Nexus 不会再以任何方式使用该实例。
+	Release(actor SessionActor)
+}
+
+// PooledProvider 包装 inner，通过 sync.Pool 复用实现了 ResettableSessionActor 的 SessionActor，以降低高连接频繁建立与断开时的 GC 压力。
+//
+// 从池中取出的实例会先调用 Reset 再交给新会话，池为空时由 inner 提供新实例；会话关闭后实例自动归还到池中，
+// 未实现 ResettableSessionActor 的实例不会被复用。Reset 必须清除所有会话级状态（包括持有的 SessionContext、
+// 定时器、goroutine 等），且实例不得在回调之外继续被引用，否则复用后会将上一个会话的状态泄漏给新会话。
+func PooledProvider(inner SessionActorProvider) SessionActorProvider {
+	return &pooledProvider{inner: inner}
+}
+
+// pooledProvider 是 PooledProvider 返回的实现。
+type pooledProvider struct {
+	inner SessionActorProvider
+	pool  sync.Pool
+}
+
+func (p *pooledProvider) Provide() (SessionActor, error) {
+	if actor, ok := p.pool.Get().(ResettableSessionActor); ok {
+		actor.Reset()
+		return actor, nil
+	}
+	return p.inner.Provide()
+}
+
+func (p *pooledProvider) Release(actor SessionActor) {
+	if resettable, ok := actor.(ResettableSessionActor); ok {
+		p.pool.Put(resettable)
+	}
+}
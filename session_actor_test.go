@@ -0,0 +1,316 @@
+package nexus_test
+
+import (
+	"errors"
+	"io"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kercylan98/vivid"
+
+	nexus "github.com/kercylan98/vivid-nexus"
+	"github.com/kercylan98/vivid-nexus/nexustest"
+)
+
+// gatedSession 在 gate 关闭前阻塞所有 Write，用于让消息滞留在出站队列中。
+type gatedSession struct {
+	*nexustest.MemorySession
+	gate chan struct{}
+}
+
+func (s *gatedSession) Write(p []byte) (int, error) {
+	<-s.gate
+	return s.MemorySession.Write(p)
+}
+
+func TestEOFCloseFlushesQueuedWrites(t *testing.T) {
+	var disconnected atomic.Bool
+	actor := &testActor{disconnected: func(ctx nexus.SessionContext) {
+		if err := ctx.Send([]byte("bye")); err != nil {
+			t.Errorf("send in OnDisconnected: %v", err)
+		}
+		disconnected.Store(true)
+	}}
+	n := newTestNexus(t, provide(actor), nexus.WithSendQueue(16))
+
+	memory := nexustest.NewMemorySession("half-open", nil)
+	session := &gatedSession{MemorySession: memory, gate: make(chan struct{})}
+	takeover(t, n, session)
+
+	for _, message := range []string{"a", "b", "c"} {
+		if err := n.Send("half-open", []byte(message)); err != nil {
+			t.Fatalf("send %q: %v", message, err)
+		}
+	}
+
+	// 对端半关闭：读循环读到 EOF 后关闭会话，此时 "a" 正在写出，"b"、"c" 仍在队列中
+	memory.CloseWrite()
+	eventually(t, "OnDisconnected", disconnected.Load)
+	close(session.gate)
+	eventually(t, "session closed", memory.Closed)
+
+	var written []string
+	for _, data := range memory.Written() {
+		written = append(written, string(data))
+	}
+	if want := []string{"a", "b", "c", "bye"}; !slices.Equal(written, want) {
+		t.Fatalf("written %q, want %q", written, want)
+	}
+
+	// 关闭后的写入不会被写出
+	if err := n.SendWait("half-open", []byte("late")); !errors.Is(err, nexus.ErrSessionClosing) && !errors.Is(err, nexus.ErrSessionNotFound) {
+		t.Fatalf("send after close returned %v, want ErrSessionClosing or ErrSessionNotFound", err)
+	}
+	if got := len(memory.Written()); got != 4 {
+		t.Fatalf("written %d messages after late send, want 4", got)
+	}
+}
+
+// TestPrelaunchFailureClosesSession 验证 sessionActor 启动失败的各条路径都会关闭底层 Session 且不注册会话。
+func TestPrelaunchFailureClosesSession(t *testing.T) {
+	errProvide := errors.New("provide failed")
+	for _, tc := range []struct {
+		name     string
+		provider nexus.SessionActorProvider
+		options  []nexus.Option
+	}{
+		{
+			name: "actor provider error",
+			provider: nexus.SessionActorProviderFN(func() (nexus.SessionActor, error) {
+				return nil, errProvide
+			}),
+		},
+		{
+			name: "actor provider nil",
+			provider: nexus.SessionActorProviderFN(func() (nexus.SessionActor, error) {
+				return nil, nil
+			}),
+		},
+		{
+			name:     "reader provider error",
+			provider: provide(&testActor{}),
+			options: []nexus.Option{nexus.WithSessionReaderProvider(nexus.SessionReaderProviderFN(func(session nexus.Session) (nexus.SessionReader, error) {
+				return nil, errProvide
+			}))},
+		},
+		{
+			name:     "reader provider nil",
+			provider: provide(&testActor{}),
+			options: []nexus.Option{nexus.WithSessionReaderProvider(nexus.SessionReaderProviderFN(func(session nexus.Session) (nexus.SessionReader, error) {
+				return nil, nil
+			}))},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			n := newTestNexus(t, tc.provider, tc.options...)
+			memory := nexustest.NewMemorySession("failed", nil)
+			if err := n.TakeoverSession(memory); err != nil {
+				t.Fatalf("takeover session: %v", err)
+			}
+			eventually(t, "session closed", memory.Closed)
+			if snapshots := n.Snapshot(); len(snapshots) != 0 {
+				t.Fatalf("%d sessions registered after prelaunch failure, want 0", len(snapshots))
+			}
+		})
+	}
+}
+
+// vividReceiveActor 额外实现了 vivid.Actor 的 OnReceive，用于验证其永远不会被调用。
+type vividReceiveActor struct {
+	testActor
+	calls atomic.Int32
+}
+
+func (a *vividReceiveActor) OnReceive(ctx vivid.ActorContext) {
+	a.calls.Add(1)
+}
+
+// TestExternalOnReceiveNeverCalled 验证 SessionActor 上的 vivid.Actor.OnReceive 在会话的整个生命周期中都不会被调用。
+func TestExternalOnReceiveNeverCalled(t *testing.T) {
+	var messages, disconnected atomic.Int32
+	actor := &vividReceiveActor{}
+	actor.testActor = testActor{
+		connected:    func(ctx nexus.SessionContext) { ctx.TellLater(0, "scheduled") },
+		message:      func(ctx nexus.SessionContext, message []byte) { messages.Add(1) },
+		disconnected: func(ctx nexus.SessionContext) { disconnected.Add(1) },
+	}
+	n := newTestNexus(t, provide(actor))
+
+	memory := nexustest.NewMemorySession("receiver", nil)
+	takeover(t, n, memory)
+	if err := memory.Feed([]byte("ping")); err != nil {
+		t.Fatalf("feed: %v", err)
+	}
+	eventually(t, "OnMessage", func() bool { return messages.Load() == 1 })
+	n.Close("receiver")
+	eventually(t, "OnDisconnected", func() bool { return disconnected.Load() == 1 })
+	if calls := actor.calls.Load(); calls != 0 {
+		t.Fatalf("vivid OnReceive called %d times, want 0", calls)
+	}
+}
+
+// zeroWriteSession 的 Write 违反 io.Writer 约定，始终返回 (0, nil)。
+type zeroWriteSession struct {
+	*nexustest.MemorySession
+}
+
+func (s *zeroWriteSession) Write(p []byte) (int, error) {
+	return 0, nil
+}
+
+// TestZeroLengthWriteIsError 验证底层 Write 返回 (0, nil) 时发送返回 io.ErrShortWrite，而不是静默丢弃消息。
+func TestZeroLengthWriteIsError(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		options []nexus.Option
+		send    func(n nexus.Nexus, sessionId string, message []byte) error
+	}{
+		{name: "send", send: nexus.Nexus.Send},
+		{name: "send wait with queue", options: []nexus.Option{nexus.WithSendQueue(4)}, send: nexus.Nexus.SendWait},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			n := newTestNexus(t, provide(&testActor{}), tc.options...)
+			takeover(t, n, &zeroWriteSession{MemorySession: nexustest.NewMemorySession("degenerate", nil)})
+			if err := tc.send(n, "degenerate", []byte("lost")); !errors.Is(err, io.ErrShortWrite) {
+				t.Fatalf("send returned %v, want io.ErrShortWrite", err)
+			}
+		})
+	}
+}
+
+// TestSelfSendFromHandler 验证在会话自身的 OnMessage 中通过 Nexus 向本会话发送消息不会死锁。
+func TestSelfSendFromHandler(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		options []nexus.Option
+	}{
+		{name: "direct write"},
+		{name: "send queue", options: []nexus.Option{nexus.WithSendQueue(4)}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var n nexus.Nexus
+			errC := make(chan error, 1)
+			n = newTestNexus(t, provide(&testActor{message: func(ctx nexus.SessionContext, message []byte) {
+				if err := ctx.Send(append([]byte("ctx:"), message...)); err != nil {
+					errC <- err
+					return
+				}
+				errC <- n.SendWait(ctx.GetSessionId(), append([]byte("nexus:"), message...))
+			}}), tc.options...)
+
+			memory := nexustest.NewMemorySession("self", nil)
+			takeover(t, n, memory)
+			if err := memory.Feed([]byte("ping")); err != nil {
+				t.Fatalf("feed: %v", err)
+			}
+			select {
+			case err := <-errC:
+				if err != nil {
+					t.Fatalf("self send: %v", err)
+				}
+			case <-time.After(testTimeout):
+				t.Fatal("self send from handler deadlocked")
+			}
+			var written []string
+			for _, data := range memory.Written() {
+				written = append(written, string(data))
+			}
+			if want := []string{"ctx:ping", "nexus:ping"}; !slices.Equal(written, want) {
+				t.Fatalf("written %q, want %q", written, want)
+			}
+		})
+	}
+}
+
+// TestHandshakeTimeout 验证启用 HandshakeTimeout 时，仅调用过 MarkHandshaked 的会话不会被关闭，且握手状态与就绪状态相互独立。
+func TestHandshakeTimeout(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		handshake  bool
+		wantClosed bool
+	}{
+		{name: "handshaked", handshake: true},
+		{name: "not handshaked", wantClosed: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var handshaked atomic.Bool
+			n := newTestNexus(t, provide(&testActor{connected: func(ctx nexus.SessionContext) {
+				if tc.handshake {
+					ctx.MarkHandshaked()
+				}
+				handshaked.Store(ctx.IsHandshaked())
+			}}), nexus.WithHandshakeTimeout(100*time.Millisecond))
+
+			memory := nexustest.NewMemorySession("handshake", nil)
+			takeover(t, n, memory)
+			if handshaked.Load() != tc.handshake {
+				t.Fatalf("IsHandshaked = %v, want %v", handshaked.Load(), tc.handshake)
+			}
+			if tc.wantClosed {
+				eventually(t, "session closed", memory.Closed)
+				return
+			}
+			time.Sleep(150 * time.Millisecond)
+			if memory.Closed() {
+				t.Fatal("handshaked session closed by handshake timeout")
+			}
+		})
+	}
+}
+
+// TestProvideNilHandlerAfterClose 验证 ProvideNilHandler 恰好调用一次，且调用时底层 Session 已被关闭。
+func TestProvideNilHandlerAfterClose(t *testing.T) {
+	var calls atomic.Int32
+	var closedOnCall atomic.Bool
+	n := newTestNexus(t, nexus.SessionActorProviderFN(func() (nexus.SessionActor, error) {
+		return nil, nil
+	}), nexus.WithOnProvideNil(func(session nexus.Session) {
+		calls.Add(1)
+		closedOnCall.Store(session.(*nexustest.MemorySession).Closed())
+	}))
+	memory := nexustest.NewMemorySession("nil-actor", nil)
+	if err := n.TakeoverSession(memory); err != nil {
+		t.Fatalf("takeover session: %v", err)
+	}
+	eventually(t, "provide nil handler called", func() bool { return calls.Load() > 0 })
+	if !closedOnCall.Load() {
+		t.Fatal("provide nil handler called before the session was closed")
+	}
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("provide nil handler called %d times, want 1", got)
+	}
+}
+
+// TestConnectCloseTimeoutKeepsMailbox 验证 OnConnected 阻塞且不做任何 I/O 时，ConnectCloseTimeout 到期只关闭底层 Session，
+// 邮箱在 OnConnected 返回前仍被占用，返回后会话被 Kill。
+func TestConnectCloseTimeoutKeepsMailbox(t *testing.T) {
+	entered, release := make(chan struct{}), make(chan struct{})
+	n := newTestNexus(t, provide(&testActor{connected: func(ctx nexus.SessionContext) {
+		close(entered)
+		<-release
+	}}), nexus.WithConnectCloseTimeout(50*time.Millisecond))
+
+	memory := nexustest.NewMemorySession("slow-connect", nil)
+	if err := n.TakeoverSession(memory); err != nil {
+		t.Fatalf("takeover session: %v", err)
+	}
+	<-entered
+	done := n.Done("slow-connect")
+	eventually(t, "session closed by connect close timeout", memory.Closed)
+
+	n.Close("slow-connect")
+	select {
+	case <-done:
+		t.Fatal("session finished closing while OnConnected was still running")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	select {
+	case <-done:
+	case <-time.After(testTimeout):
+		t.Fatal("session not killed after OnConnected returned")
+	}
+}
@@ -0,0 +1,70 @@
+package nexus
+
+import "bytes"
+
+// askWaiter 表示一次 Ask 调用注册在会话上的等待者。
+type askWaiter struct {
+	match func(message []byte) bool // 判断入站消息是否为本次 Ask 的回复
+	reply chan []byte               // 容量为 1，匹配成功时投递回复的拷贝，会话关闭时被 close
+}
+
+func newAskWaiter(match func(message []byte) bool) *askWaiter {
+	return &askWaiter{
+		match: match,
+		reply: make(chan []byte, 1),
+	}
+}
+
+// addWaiter 注册等待者，会话已关闭时返回 false。
+func (info *sessionInfo) addWaiter(waiter *askWaiter) bool {
+	info.waiterLock.Lock()
+	defer info.waiterLock.Unlock()
+
+	if info.waiterClosed {
+		return false
+	}
+	info.waiters = append(info.waiters, waiter)
+	return true
+}
+
+// removeWaiter 移除等待者，等待者不存在时无操作。
+func (info *sessionInfo) removeWaiter(waiter *askWaiter) {
+	info.waiterLock.Lock()
+	defer info.waiterLock.Unlock()
+
+	for i, w := range info.waiters {
+		if w == waiter {
+			info.waiters = append(info.waiters[:i], info.waiters[i+1:]...)
+			return
+		}
+	}
+}
+
+// resolveWaiter 按注册顺序查找首个匹配 message 的等待者，匹配成功时投递 message 的拷贝并返回 true。
+//
+// 由 sessionActor 在邮箱线程中调用，message 仅在本次调用内有效，故投递前必须拷贝。
+func (info *sessionInfo) resolveWaiter(message []byte) bool {
+	info.waiterLock.Lock()
+	defer info.waiterLock.Unlock()
+
+	for i, w := range info.waiters {
+		if w.match(message) {
+			info.waiters = append(info.waiters[:i], info.waiters[i+1:]...)
+			w.reply <- bytes.Clone(message)
+			return true
+		}
+	}
+	return false
+}
+
+// closeWaiters 在会话关闭时唤醒所有等待者，此后的 addWaiter 均返回 false。
+func (info *sessionInfo) closeWaiters() {
+	info.waiterLock.Lock()
+	defer info.waiterLock.Unlock()
+
+	info.waiterClosed = true
+	for _, w := range info.waiters {
+		close(w.reply)
+	}
+	info.waiters = nil
+}
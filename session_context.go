@@ -1,18 +1,45 @@
 package nexus
 
-import "github.com/kercylan98/vivid"
+import (
+	"crypto/tls"
+	"time"
+
+	"github.com/kercylan98/vivid"
+	"github.com/kercylan98/vivid/pkg/log"
+)
 
 // SessionContext 在 OnConnected、OnMessage、OnDisconnected 中提供当前会话的上下文。
 //
 // 内嵌 vivid.ActorContext，并扩展本会话的 ID、关闭、发送及接入层传入的元数据访问。
 type SessionContext interface {
 	vivid.ActorContext
+	// Logger 返回绑定了 session_id 字段的会话级日志，覆盖内嵌 vivid.ActorContext 的 Logger，便于按会话聚合日志。
+	Logger() log.Logger
 	// GetSessionId 返回本会话的唯一标识。
 	GetSessionId() string
 	// Close 关闭本会话。
 	Close()
+	// CloseWithCode 以关闭码与原因关闭本会话，底层 Session 实现 CodedCloser 时据此关闭连接（如 WebSocket 关闭帧），否则等同于 Close。
+	CloseWithCode(code int, reason string)
 	// Send 向本会话发送数据，会话已关闭时返回 error。
 	Send(message []byte) error
+	// BroadcastOthers 向除本会话外的所有托管会话推送 message，语义同 Nexus.Broadcast。
+	BroadcastOthers(message []byte)
+	// SendToOthers 向 sessionIds 中除本会话外的会话推送 message，语义同 Nexus.SendTo。
+	SendToOthers(sessionIds []string, message []byte)
+	// SendText 以文本帧向本会话发送数据，覆盖默认帧类型，其余语义同 Send。
+	SendText(message []byte) error
+	// SendBinary 以二进制帧向本会话发送数据，覆盖默认帧类型，其余语义同 Send。
+	SendBinary(message []byte) error
+	// SetDefaultFrameKind 设置本会话 Send 等未显式指定帧类型的写出所使用的帧类型，通常在 OnConnected 中调用；
+	// 底层 Session 未实现 FrameKindWriter 时不产生影响。并发安全。
+	SetDefaultFrameKind(kind FrameKind)
+	// BufferWrite 将 data 追加到本会话的写缓冲区，待 Flush 时一次性写出；
+	// 缓冲区累计大小达到 Options.WriteBufferFlushThreshold 时自动 Flush 并返回其错误。
+	// 缓冲区中的数据与 Send 相互独立，需要保证先后顺序时应先 Flush 再 Send。
+	BufferWrite(data []byte) error
+	// Flush 将写缓冲区中的数据一次性写入底层 Session，缓冲区为空时直接返回 nil。
+	Flush() error
 	// GetMetadata 返回 key 对应的元数据值，不存在返回 nil。
 	GetMetadata(key string) any
 	// GetMetadataWithDefault 返回 key 对应的元数据值，不存在返回 defaultValue。
@@ -21,22 +48,109 @@ type SessionContext interface {
 	GetMetadataWithExists(key string) (any, bool)
 	// HasMetadata 报告 key 是否存在于元数据中。
 	HasMetadata(key string) bool
+	// SetOwner 将本会话归属到 key 对应的所有者（如用户 ID）下，便于通过 SendToOwner、CloseOwner 按所有者寻址；
+	// 重复调用会覆盖原归属，key 为空时清除归属。会话关闭后自动从索引中移除。
+	SetOwner(key string)
+	// GetOwner 返回本会话当前的所有者标识，未设置时返回空字符串。
+	GetOwner() string
+	// JoinRoom 将本会话加入 room，重复加入无操作；会话关闭后自动退出所有房间。
+	JoinRoom(room string)
+	// LeaveRoom 将本会话移出 room，未加入时无操作。
+	LeaveRoom(room string)
+	// InRoom 报告本会话是否在 room 中。
+	InRoom(room string) bool
+	// SetTags 以 tags 覆盖本会话的标签集合，便于通过 BroadcastTag 按标签定向广播；不传参数时清空标签。
+	SetTags(tags ...string)
+	// HasTag 报告本会话是否带有 tag。
+	HasTag(tag string) bool
+	// BytesIn 返回本会话累计读取的字节数，按 SessionReader 每次返回的 n 统计，反映传输层实际读取量。
+	BytesIn() uint64
+	// BytesOut 返回本会话累计写出的字节数，按底层 Session.Write 返回的 n 统计。
+	BytesOut() uint64
+	// SwapActor 将本会话的 SessionActor 替换为 newActor，用于在认证等协议阶段切换后改变会话行为，无需重建会话。
+	// 默认依次调用原 Actor 的 OnDisconnected 与 newActor 的 OnConnected；newActor 实现 SwappedSessionActor 时仅调用 OnSwapped。
+	// 只能在本会话的回调中（邮箱线程）调用；newActor 为 nil 或会话已关闭时返回 error。
+	SwapActor(newActor SessionActor) error
+	// MarkHandshaked 标记本会话已完成业务握手（如认证），此后不再受 Options.HandshakeTimeout 限制；重复调用无操作，并发安全。
+	//
+	// 业务握手状态与 SessionSnapshot.Ready 表示的就绪状态相互独立：后者由 Nexus 维护，表示 OnConnected 已完成且会话尚未开始关闭，
+	// 决定 WithBroadcastReadyOnly 下会话能否接收广播；调用 MarkHandshaked 不会改变就绪状态，未完成握手的会话同样可能处于就绪状态。
+	MarkHandshaked()
+	// IsHandshaked 报告本会话是否已调用过 MarkHandshaked。
+	IsHandshaked() bool
+	// TellLater 在 delay 后将 message 投递到本会话的邮箱，由 ReceiveSessionActor.OnReceive 处理；会话先于到期关闭时自动取消。
+	// 用于调度与会话生命周期绑定的延时动作（如 30 秒后提醒），避免业务自行创建比会话存活更久的定时器。并发安全。
+	TellLater(delay time.Duration, message any)
+	// NextSeq 返回本会话下一个出站序号，从 1 开始单调递增，便于为出站消息标记会话内唯一的消息 ID，并发安全。
+	// 序号按需分配：仅在调用 NextSeq 或 DeliveryNexus.SendWithAck 时递增，二者共用同一计数器，因此序号在两者之间也不会重复；
+	// Send 等普通写出、分帧与控制帧（如 Ping/Pong）均不消耗序号。
+	NextSeq() uint64
+	// PendingWrites 返回本会话出站队列中尚未写出的消息数量，未启用 WithSendQueue 时总是返回 0，并发安全。
+	PendingWrites() int
+	// HandlerBusy 报告本会话是否有已读取、但尚未被 OnMessage（或 OnMessages）处理完成的入站消息，并发安全。
+	HandlerBusy() bool
+	// IsReplaced 报告本会话是否因同 ID 的新会话接管而被关闭，可在 OnDisconnected 中区分被替换与普通断开。
+	IsReplaced() bool
+	// SetReadLimit 将本会话单条入站消息的上限下发给实现了 ReadLimiter 的底层 Session，返回是否支持；
+	// 仅影响传输层，Options.MaxMessageSize 的校验不受影响。
+	SetReadLimit(limit int64) bool
+	// SwapReader 替换读循环使用的 SessionReader，用于连接建立后才协商分帧方式的协议（如文本握手后切换为二进制分帧）。
+	// 替换在两次 Read 之间生效：在 OnMessage 中调用时立即完成，下一条消息即由新 Reader 读取；在其他 goroutine 中调用时等待进行中的 Read 返回。
+	// 旧 Reader 已预读但尚未返回的数据会丢失，协商阶段应使用不预读的 Reader；被替换的 Reader 在会话结束后 Close（如实现了 io.Closer）。
+	// reader 为 nil 时返回错误，会话已关闭时返回 ErrSessionClosed，启用 MessageBatchSize 或禁用读循环时返回 ErrReaderSwapUnsupported。
+	SwapReader(reader SessionReader) error
+	// PauseReading 暂停读取本会话的入站数据：读循环在当前消息处理完成后阻塞，直到 ResumeReading 或会话关闭；
+	// 重复调用无操作，并发安全。暂停期间数据滞留在传输层，可借助对端的流控实现背压。
+	PauseReading()
+	// ResumeReading 恢复被 PauseReading 暂停的读取，未暂停时无操作，并发安全。
+	ResumeReading()
+	// TLSConnectionState 返回底层 Session 的 TLS 连接状态（含对端证书与 SNI），底层 Session 未实现 TLSInfoSession 时 ok 为 false。
+	TLSConnectionState() (state tls.ConnectionState, ok bool)
+	// GetSessionReader 返回 SessionReaderProvider 为本会话提供的 SessionReader，
+	// 可通过类型断言判断当前会话所使用的协议。
+	GetSessionReader() SessionReader
 }
 
 // sessionContext 实现 SessionContext。
 type sessionContext struct {
 	*sessionInfo
 	vivid.ActorContext
+	sessionActor *sessionActor // 所属的 sessionActor，用于访问会话级运行时状态
 }
 
 func (c *sessionContext) Close() {
 	c.sessionInfo.operator.Close(c.GetSessionId())
 }
 
+func (c *sessionContext) CloseWithCode(code int, reason string) {
+	c.sessionInfo.operator.CloseWithCode(c.GetSessionId(), code, reason)
+}
+
 func (c *sessionContext) Send(message []byte) error {
 	return c.sessionInfo.operator.Send(c.GetSessionId(), message)
 }
 
+func (c *sessionContext) BufferWrite(data []byte) error {
+	if len(data) == 0 {
+		return nil
+	}
+
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+
+	c.writeBuffer = append(c.writeBuffer, data...)
+	if threshold := c.operator.actor.options.WriteBufferFlushThreshold; threshold > 0 && len(c.writeBuffer) >= threshold {
+		return c.flush()
+	}
+	return nil
+}
+
+func (c *sessionContext) Flush() error {
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+	return c.flush()
+}
+
 func (c *sessionContext) GetSessionId() string {
 	return c.Session.GetSessionId()
 }
@@ -60,3 +174,153 @@ func (c *sessionContext) GetMetadataWithExists(key string) (any, bool) {
 func (c *sessionContext) HasMetadata(key string) bool {
 	return c.sessionInfo.metadata[key] != nil
 }
+
+func (c *sessionContext) GetSessionReader() SessionReader {
+	c.sessionActor.readerLock.RLock()
+	defer c.sessionActor.readerLock.RUnlock()
+	return c.sessionActor.reader
+}
+
+func (c *sessionContext) SwapReader(reader SessionReader) error {
+	return c.sessionActor.swapReader(reader)
+}
+
+func (c *sessionContext) BytesIn() uint64 {
+	return c.bytesIn.Load()
+}
+
+func (c *sessionContext) BytesOut() uint64 {
+	return c.bytesOut.Load()
+}
+
+func (c *sessionContext) SetOwner(key string) {
+	c.operator.setOwner(c.sessionInfo, key)
+}
+
+func (c *sessionContext) GetOwner() string {
+	c.operator.actor.sessionLock.RLock()
+	defer c.operator.actor.sessionLock.RUnlock()
+	return c.owner
+}
+
+func (c *sessionContext) SwapActor(newActor SessionActor) error {
+	return c.sessionActor.swapActor(newActor)
+}
+
+func (c *sessionContext) JoinRoom(room string) {
+	c.operator.joinRoom(c.sessionInfo, room)
+}
+
+func (c *sessionContext) LeaveRoom(room string) {
+	c.operator.leaveRoom(c.sessionInfo, room)
+}
+
+func (c *sessionContext) InRoom(room string) bool {
+	c.operator.actor.sessionLock.RLock()
+	defer c.operator.actor.sessionLock.RUnlock()
+	_, ok := c.rooms[room]
+	return ok
+}
+
+func (c *sessionContext) SetTags(tags ...string) {
+	c.operator.setTags(c.sessionInfo, tags)
+}
+
+func (c *sessionContext) HasTag(tag string) bool {
+	c.operator.actor.sessionLock.RLock()
+	defer c.operator.actor.sessionLock.RUnlock()
+	_, ok := c.tags[tag]
+	return ok
+}
+
+func (c *sessionContext) MarkHandshaked() {
+	c.handshaked.Store(true)
+}
+
+func (c *sessionContext) IsHandshaked() bool {
+	return c.handshaked.Load()
+}
+
+func (c *sessionContext) PauseReading() {
+	c.sessionActor.pauseReading()
+}
+
+func (c *sessionContext) ResumeReading() {
+	c.sessionActor.resumeReading()
+}
+
+func (c *sessionContext) Logger() log.Logger {
+	if logger := c.sessionActor.logger; logger != nil {
+		return logger
+	}
+	return c.ActorContext.Logger()
+}
+
+func (c *sessionContext) TellLater(delay time.Duration, message any) {
+	c.sessionActor.tellLater(delay, message)
+}
+
+func (c *sessionContext) SendText(message []byte) error {
+	if len(message) == 0 {
+		return nil
+	}
+	return c.sendFrame(FrameKindText, message)
+}
+
+func (c *sessionContext) SendBinary(message []byte) error {
+	if len(message) == 0 {
+		return nil
+	}
+	return c.sendFrame(FrameKindBinary, message)
+}
+
+func (c *sessionContext) SetDefaultFrameKind(kind FrameKind) {
+	c.frameKind.Store(uint32(kind))
+}
+
+func (c *sessionContext) IsReplaced() bool {
+	return c.replaced.Load()
+}
+
+func (c *sessionContext) SetReadLimit(limit int64) bool {
+	limiter, ok := unwrapSession[ReadLimiter](c.session())
+	if ok {
+		limiter.SetReadLimit(limit)
+	}
+	return ok
+}
+
+func (c *sessionContext) BroadcastOthers(message []byte) {
+	c.SendToOthers(c.operator.sessionIds(), message)
+}
+
+func (c *sessionContext) SendToOthers(sessionIds []string, message []byte) {
+	self := c.GetSessionId()
+	others := make([]string, 0, len(sessionIds))
+	for _, sessionId := range sessionIds {
+		if sessionId != self {
+			others = append(others, sessionId)
+		}
+	}
+	c.operator.sendTo(others, message, nil)
+}
+
+func (c *sessionContext) NextSeq() uint64 {
+	return c.nextSeq()
+}
+
+func (c *sessionContext) PendingWrites() int {
+	return c.pendingWrites()
+}
+
+func (c *sessionContext) HandlerBusy() bool {
+	return c.handlerBusy.Load()
+}
+
+func (c *sessionContext) TLSConnectionState() (state tls.ConnectionState, ok bool) {
+	session, ok := unwrapSession[TLSInfoSession](c.session())
+	if !ok {
+		return state, false
+	}
+	return session.ConnectionState(), true
+}
@@ -0,0 +1,46 @@
+package nexus
+
+// ControlKind 表示协议层控制帧的类型，如 WebSocket 的 ping/pong。
+type ControlKind uint8
+
+const (
+	// ControlPing 表示 ping 帧，Nexus 会自动以携带相同负载的 pong 帧回复。
+	ControlPing ControlKind = iota + 1
+	// ControlPong 表示 pong 帧。
+	ControlPong
+)
+
+// String 返回控制帧类型的可读名称。
+func (k ControlKind) String() string {
+	switch k {
+	case ControlPing:
+		return "ping"
+	case ControlPong:
+		return "pong"
+	default:
+		return "unknown"
+	}
+}
+
+// ControlSession 是 Session 的可选扩展，由具有协议层控制帧的传输层（如 WebSocket）实现，使心跳无需经过业务代码。
+//
+// Nexus 在会话启动、读循环开始前调用 SetControlHandler 注册处理函数：收到 ping 时自动调用 WriteControl 回复 pong，
+// 收到 ping 或 pong 时均刷新会话的最近活动时间；控制帧不会被投递给 SessionActor.OnMessage。
+type ControlSession interface {
+	Session
+	// SetControlHandler 注册控制帧处理函数，实现方在读取到控制帧时调用 handler（通常在读循环所在的 goroutine 中）。
+	SetControlHandler(handler func(kind ControlKind, payload []byte))
+	// WriteControl 写出一个控制帧，需要与 Write 并发安全或由实现方自行串行化。
+	WriteControl(kind ControlKind, payload []byte) error
+}
+
+// handleControl 处理会话收到的控制帧：刷新最近活动时间，收到 ping 时回复 pong；会话已进入关闭流程时不再回复。
+func (info *sessionInfo) handleControl(session ControlSession, kind ControlKind, payload []byte) {
+	info.touch()
+	if kind != ControlPing || info.closing.Load() {
+		return
+	}
+	info.writeLock.Lock()
+	defer info.writeLock.Unlock()
+	_ = session.WriteControl(ControlPong, payload)
+}
@@ -0,0 +1,56 @@
+package nexus
+
+import "time"
+
+// inboundDedup 记录单个会话在去重窗口内见过的入站消息键，仅在会话的邮箱线程中访问。
+//
+// 内存占用与窗口内不同消息的数量成正比：每条消息在 seen 与 order 中各占一项，过期项在后续消息到达时按时间顺序淘汰。
+type inboundDedup struct {
+	window time.Duration
+	keyFn  func(message []byte) string
+	seen   map[string]time.Time // 键最近一次出现的时间
+	order  []dedupEntry         // 按出现时间排列的键，用于淘汰过期项
+}
+
+type dedupEntry struct {
+	key string
+	at  time.Time
+}
+
+func newInboundDedup(window time.Duration, keyFn func(message []byte) string) *inboundDedup {
+	if keyFn == nil {
+		keyFn = func(message []byte) string {
+			return string(message)
+		}
+	}
+	return &inboundDedup{
+		window: window,
+		keyFn:  keyFn,
+		seen:   make(map[string]time.Time),
+	}
+}
+
+// duplicate 报告 message 的键是否在 now 之前的窗口内出现过；未出现时记录该键。
+func (d *inboundDedup) duplicate(message []byte, now time.Time) bool {
+	d.expire(now)
+	key := d.keyFn(message)
+	if _, ok := d.seen[key]; ok {
+		return true
+	}
+	d.seen[key] = now
+	d.order = append(d.order, dedupEntry{key: key, at: now})
+	return false
+}
+
+// expire 淘汰出现时间早于窗口的键。
+func (d *inboundDedup) expire(now time.Time) {
+	var i int
+	for ; i < len(d.order) && now.Sub(d.order[i].at) >= d.window; i++ {
+		if at, ok := d.seen[d.order[i].key]; ok && at.Equal(d.order[i].at) {
+			delete(d.seen, d.order[i].key)
+		}
+	}
+	if i > 0 {
+		d.order = append(d.order[:0], d.order[i:]...)
+	}
+}
@@ -0,0 +1,50 @@
+package nexus
+
+import "time"
+
+// ReadDeadlineSession 是支持设置读超时的 Session（如 net.Conn），Detach 依赖它在不关闭连接的情况下中断进行中的读取。
+type ReadDeadlineSession interface {
+	Session
+	// SetReadDeadline 设置读超时，零值表示不超时。
+	SetReadDeadline(t time.Time) error
+}
+
+// Detach 将指定 ID 的会话移出托管并停止其 sessionActor，但不关闭底层 Session，而是将其返回以便交由其他 Nexus 重新接管。
+//
+// 用于集群/分片场景下在不断开连接的情况下迁移会话。会话不存在或尚未就绪时返回 ErrSessionNotFound；
+// 底层 Session 未实现 ReadDeadlineSession 时返回 ErrDetachUnsupported，此时会话保持不变。
+//
+// 分离过程：先将会话移出托管，再 Kill sessionActor 并以立即到期的读超时中断读循环；sessionActor 照常调用 OnDisconnected
+// 并写出剩余缓冲，但不关闭底层 Session。Detach 会阻塞直到 sessionActor 完成关闭且所有读取 goroutine 退出，之后清除读超时并返回。
+// 因此不可在被分离会话自身的回调中调用。SessionReader 中已预读但尚未投递的数据会随 sessionActor 一并丢弃，
+// 需要迁移的会话应使用不做预读的 SessionReader。
+func (o *operator) Detach(sessionId string) (Session, error) {
+	o.actor.sessionLock.Lock()
+	info, ok := o.actor.sessions[sessionId]
+	if !ok || !info.ready.Load() {
+		o.actor.sessionLock.Unlock()
+		return nil, ErrSessionNotFound
+	}
+	deadlineSession, ok := unwrapSession[ReadDeadlineSession](info.session())
+	if !ok {
+		o.actor.sessionLock.Unlock()
+		return nil, ErrDetachUnsupported
+	}
+	info.detached.Store(true)
+	o.actor.unregisterSession(sessionId, info)
+	o.actor.emitEvent(SessionEventClosed, sessionId, nil)
+	o.actorContext.Kill(info.ref, false, o.actor.options.formatReason(ReasonDetach, ""))
+	o.actor.sessionLock.Unlock()
+
+	if err := deadlineSession.SetReadDeadline(time.Now()); err != nil {
+		// 无法中断读取时 sessionActor 已被 Kill，只能关闭连接以释放读循环
+		_ = info.closeSession()
+		return nil, err
+	}
+	<-info.done
+	info.context.sessionActor.readers.Wait()
+	if err := deadlineSession.SetReadDeadline(time.Time{}); err != nil {
+		return nil, err
+	}
+	return info.session(), nil
+}
@@ -0,0 +1,64 @@
+package nexus
+
+import "time"
+
+// defaultEventBufferSize 为 Options.EventBufferSize 未设置时生命周期事件通道的默认容量。
+const defaultEventBufferSize = 1024
+
+// SessionEventType 表示会话生命周期事件的类型。
+type SessionEventType int
+
+const (
+	// SessionEventOpened 表示会话已被接管并完成 sessionActor 创建。
+	SessionEventOpened SessionEventType = iota + 1
+	// SessionEventClosed 表示会话已结束并从托管中移除。
+	SessionEventClosed
+	// SessionEventReplaced 表示会话被同 sessionId 的新会话替换，旧会话随后会被关闭。
+	SessionEventReplaced
+	// SessionEventResumed 表示处于 EOF 宽限期的会话被同 sessionId 的新连接恢复（见 EOFPolicyGrace）。
+	SessionEventResumed
+	// SessionEventError 表示会话因错误被拒绝、启动失败或由业务返回错误而关闭，Err 为具体原因。
+	SessionEventError
+)
+
+// String 返回事件类型的可读名称。
+func (t SessionEventType) String() string {
+	switch t {
+	case SessionEventOpened:
+		return "opened"
+	case SessionEventClosed:
+		return "closed"
+	case SessionEventReplaced:
+		return "replaced"
+	case SessionEventResumed:
+		return "resumed"
+	case SessionEventError:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
+// SessionEvent 是通过 Nexus.Events 发出的会话生命周期事件。
+type SessionEvent struct {
+	Type      SessionEventType // 事件类型
+	SessionId string           // 事件所属的会话 ID
+	Time      time.Time        // 事件产生的时间
+	Err       error            // 仅 SessionEventError 携带的错误原因
+}
+
+// Events 返回会话生命周期事件通道，所有调用返回同一个通道。
+//
+// 通道容量由 Options.EventBufferSize 决定；为避免阻塞会话处理的热路径，通道已满时新事件会被直接丢弃，
+// 因此使用方应持续消费。通道在 Nexus 生命周期内不会被关闭。
+func (o *operator) Events() <-chan SessionEvent {
+	return o.actor.events
+}
+
+// emitEvent 以非阻塞方式发出事件，通道已满时丢弃。
+func (n *Actor) emitEvent(eventType SessionEventType, sessionId string, err error) {
+	select {
+	case n.events <- SessionEvent{Type: eventType, SessionId: sessionId, Time: time.Now(), Err: err}:
+	default:
+	}
+}
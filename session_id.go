@@ -0,0 +1,57 @@
+package nexus
+
+import "github.com/google/uuid"
+
+// NewSessionIdGenerator 返回生成 UUID（v4）格式随机 ID 的 sessionId 生成器，可直接传给 WithSessionIdGenerator。
+func NewSessionIdGenerator() func() string {
+	return uuid.NewString
+}
+
+// NewSessionWithId 包装 session，使其 GetSessionId 返回 sessionId，其余方法委托给原 session。
+//
+// 返回值始终实现 MetadataSession：原 session 实现了 MetadataSession 时委托其 Metadata，否则返回 nil。
+// Nexus 在启用 WithSessionIdGenerator 时使用该包装为不携带 ID 的会话分配 ID，接入层也可直接使用；
+// Nexus 识别 ControlSession、CodedCloser、ReadDeadlineSession 等可选扩展接口时会经 Unwrap 查找原 session，
+// 因此包装不会使这些能力失效。业务需要访问原 session 时同样可通过 Unwrap 取回。
+func NewSessionWithId(session Session, sessionId string) MetadataSession {
+	return &identifiedSession{Session: session, sessionId: sessionId}
+}
+
+// identifiedSession 是 NewSessionWithId 返回的包装。
+type identifiedSession struct {
+	Session
+	sessionId string
+}
+
+func (s *identifiedSession) GetSessionId() string {
+	return s.sessionId
+}
+
+func (s *identifiedSession) Metadata() map[string]any {
+	if metadataSession, ok := s.Session.(MetadataSession); ok {
+		return metadataSession.Metadata()
+	}
+	return nil
+}
+
+// Unwrap 返回被包装的原 session。
+func (s *identifiedSession) Unwrap() Session {
+	return s.Session
+}
+
+// unwrapSession 在 session 及其经 Unwrap 逐层包装的原 session 中查找首个实现了 T 的实例，
+// 使 NewSessionWithId 等包装之下的可选扩展接口仍能被识别。
+func unwrapSession[T any](session Session) (T, bool) {
+	for session != nil {
+		if target, ok := session.(T); ok {
+			return target, true
+		}
+		wrapper, ok := session.(interface{ Unwrap() Session })
+		if !ok {
+			break
+		}
+		session = wrapper.Unwrap()
+	}
+	var zero T
+	return zero, false
+}
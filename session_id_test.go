@@ -0,0 +1,52 @@
+package nexus_test
+
+import (
+	"sync"
+	"testing"
+
+	nexus "github.com/kercylan98/vivid-nexus"
+	"github.com/kercylan98/vivid-nexus/nexustest"
+)
+
+// codedSession 是实现 CodedCloser 的 MemorySession，记录 CloseWithCode 收到的关闭码。
+type codedSession struct {
+	*nexustest.MemorySession
+	lock sync.Mutex
+	code int
+}
+
+func (s *codedSession) CloseWithCode(code int, reason string) error {
+	s.lock.Lock()
+	s.code = code
+	s.lock.Unlock()
+	return s.Close()
+}
+
+func (s *codedSession) closedCode() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.code
+}
+
+// TestGeneratedSessionIdKeepsExtensions 验证由 WithSessionIdGenerator 分配 ID 的会话仍能识别原 session 的可选扩展接口。
+func TestGeneratedSessionIdKeepsExtensions(t *testing.T) {
+	connected := make(chan string, 1)
+	n := newTestNexus(t, provide(&testActor{
+		connected: func(ctx nexus.SessionContext) { connected <- ctx.GetSessionId() },
+	}), nexus.WithSessionIdGenerator(nexus.NewSessionIdGenerator()))
+
+	session := &codedSession{MemorySession: nexustest.NewMemorySession("", nil)}
+	if err := n.TakeoverSession(session); err != nil {
+		t.Fatalf("takeover session: %v", err)
+	}
+	sessionId := <-connected
+	if len(sessionId) != 36 {
+		t.Fatalf("generated session id %q is not a uuid", sessionId)
+	}
+
+	n.(nexus.CloseNexus).CloseWithCode(sessionId, 4001, "kicked")
+	eventually(t, "session closed", session.Closed)
+	if code := session.closedCode(); code != 4001 {
+		t.Fatalf("close code = %d, want 4001", code)
+	}
+}
@@ -1,16 +1,37 @@
 package nexus
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
 	"maps"
+	"os"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/kercylan98/vivid"
 )
 
 func newSessionInfo(operator *operator, session Session) *sessionInfo {
 	info := &sessionInfo{
-		operator: operator,
-		Session:  session,
+		operator:    operator,
+		writeLock:   make(writeMutex, 1),
+		Session:     session,
+		connectedAt: time.Now(),
+		done:        make(chan struct{}),
+	}
+	info.current.Store(&session)
+	info.lastActivity.Store(info.connectedAt.UnixNano())
+	if size := operator.actor.options.SendQueueSize; size > 0 {
+		// 写循环随队列创建，使 sessionActor 启动前入队的消息（如 SendWait）也能被写出
+		info.queue = newSendQueue(size)
+		info.writerDone = make(chan struct{})
+		go info.writeLoop()
+	}
+	if rate := operator.actor.options.OutboundRateLimit; rate > 0 {
+		info.outboundLimiter = newTokenBucket(rate, operator.actor.options.OutboundBurst)
 	}
 	if metadataSession, ok := session.(MetadataSession); ok {
 		info.metadata = maps.Clone(metadataSession.Metadata())
@@ -20,8 +41,465 @@ func newSessionInfo(operator *operator, session Session) *sessionInfo {
 
 type sessionInfo struct {
 	*operator
-	Session
-	ref       vivid.ActorRef // Session 自身对应 ActorRef
-	writeLock sync.Mutex     // 写锁，用于保证写操作的顺序性
-	metadata  map[string]any // 元数据，用于在回调间携带业务状态
+	Session                                       // 接管时的 Session，仅用于提供会话 ID；读写及可选扩展接口需经 session 访问当前连接
+	current         atomic.Pointer[Session]       // 当前的底层连接，EOFPolicyGrace 下恢复会话时被替换
+	ref             vivid.ActorRef                // Session 自身对应 ActorRef
+	context         *sessionContext               // 本会话的 SessionContext，由 newSessionActor 绑定
+	writeLock       writeMutex                    // 写锁，用于保证写操作的顺序性
+	writeBuffer     []byte                        // BufferWrite 的写缓冲区，由 writeLock 保护
+	frameBuffer     []byte                        // 出站分帧的复用缓冲区，由 writeLock 保护
+	outboundLimiter *tokenBucket                  // 出站字节限流器，未启用 OutboundRateLimit 时为 nil，由 writeLock 保护
+	queue           *sendQueue                    // 出站优先级队列，未启用 Options.SendQueueSize 时为 nil
+	writerDone      chan struct{}                 // 写循环退出时关闭，未启用出站队列时为 nil
+	metadata        map[string]any                // 元数据，用于在回调间携带业务状态
+	key             SessionKey                    // 由 SessionKeyer 提供的结构化标识，未实现时为 nil
+	owner           string                        // 所有者标识，由 SetOwner 设置，受 Nexus 的 sessionLock 保护
+	rooms           map[string]struct{}           // 已加入的房间，受 Nexus 的 sessionLock 保护
+	tags            map[string]struct{}           // 会话标签，受 Nexus 的 sessionLock 保护
+	ready           atomic.Bool                   // OnConnected 完成后置为 true，开始关闭时置为 false
+	closeMode       atomic.Uint32                 // 关闭方式（closeMode），由首个 markForceClose 或开始优雅关闭的 onKill 决定
+	handshaked      atomic.Bool                   // 由 MarkHandshaked 设置，表示业务握手已完成
+	replaced        atomic.Bool                   // 因同 ID 的新会话接管而被关闭时置为 true
+	detached        atomic.Bool                   // 由 Detach 设置，关闭时不关闭底层 Session
+	frameKind       atomic.Uint32                 // 默认帧类型（FrameKind），由 SetDefaultFrameKind 设置
+	handlerBusy     atomic.Bool                   // 读循环投递的入站消息尚未处理完成时为 true
+	suspended       atomic.Bool                   // EOFPolicyGrace 下读到 EOF 后置为 true，等待同 ID 的新连接恢复
+	resume          atomic.Pointer[sessionResume] // 已交给本会话但尚未被处理的恢复请求
+	writeFailed     atomic.Bool                   // 禁用读循环时首次写出失败后置为 true，避免重复关闭
+	closing         atomic.Bool                   // 进入关闭流程后置为 true，此后的写入返回 ErrSessionClosing
+	bytesIn         atomic.Uint64                 // 累计读取的字节数，按 SessionReader 返回的 n 统计
+	bytesOut        atomic.Uint64                 // 累计写出的字节数，按 Session.Write 返回的 n 统计
+	connectedAt     time.Time                     // 会话被接管的时间
+	lastActivity    atomic.Int64                  // 最近一次读写数据的时间（UnixNano）
+
+	waiterLock   sync.Mutex   // 保护 waiters 与 waiterClosed
+	waiters      []*askWaiter // Ask 注册的等待者，按注册顺序匹配入站消息
+	waiterClosed bool         // 会话关闭后置为 true，不再接受新的等待者
+
+	outSeq  atomic.Uint64            // 出站序号计数器，由 NextSeq 与 SendWithAck 共享
+	ackLock sync.Mutex               // 保护 acks
+	acks    map[uint64]chan struct{} // 等待确认的序号，确认到达时关闭对应通道
+
+	done     chan struct{} // 会话终止（完成关闭或 Actor 被移除）时关闭
+	doneOnce sync.Once     // 确保 done 只被关闭一次
+
+	closeOnce  sync.Once                  // 确保底层 Session 只被关闭一次
+	closeFrame atomic.Pointer[closeFrame] // CloseWithCode 请求的关闭码与原因，首次请求生效
+	closeErr   error                      // 首次关闭底层 Session 的结果
+}
+
+// session 返回当前的底层连接。EOFPolicyGrace 下恢复会话时底层连接会被替换，读写、关闭与可选扩展接口的识别均应经由该方法。
+func (info *sessionInfo) session() Session {
+	return *info.current.Load()
+}
+
+// closeSession 关闭底层 Session 并返回首次关闭的结果，可重复调用，底层 Session.Close 只会执行一次。
+func (info *sessionInfo) closeSession() error {
+	info.closeOnce.Do(func() {
+		if frame := info.closeFrame.Load(); frame != nil {
+			if closer, ok := unwrapSession[CodedCloser](info.session()); ok {
+				info.closeErr = closer.CloseWithCode(frame.code, frame.reason)
+				return
+			}
+		}
+		info.closeErr = info.session().Close()
+	})
+	return info.closeErr
+}
+
+// 会话的关闭方式，一经决定不再改变。
+const (
+	closeModeUndecided uint32 = iota // 尚未开始关闭
+	closeModeGraceful                // onKill 已开始优雅关闭：调用 OnDisconnected 并写出剩余缓冲
+	closeModeForce                   // 关闭时跳过 OnDisconnected 与缓冲写出，由 ForceClose 或 Drain 超时设置
+)
+
+// markForceClose 在会话尚未开始优雅关闭时将其标记为强制关闭并返回 true；优雅关闭已经开始时返回 false，此时标记不会改变关闭结果。
+func (info *sessionInfo) markForceClose() bool {
+	return info.closeMode.CompareAndSwap(closeModeUndecided, closeModeForce) || info.closeMode.Load() == closeModeForce
+}
+
+// beginGracefulClose 由 onKill 调用，在会话未被标记为强制关闭时决定以优雅方式关闭并返回 true。
+func (info *sessionInfo) beginGracefulClose() bool {
+	return info.closeMode.CompareAndSwap(closeModeUndecided, closeModeGraceful)
+}
+
+// closeFrame 是 CloseWithCode 请求的关闭码与原因。
+type closeFrame struct {
+	code   int
+	reason string
+}
+
+// touch 将最近活动时间更新为当前时间。
+func (info *sessionInfo) touch() {
+	info.lastActivity.Store(time.Now().UnixNano())
+}
+
+// closeDone 标记会话已终止，可重复调用。
+func (info *sessionInfo) closeDone() {
+	info.doneOnce.Do(func() {
+		close(info.done)
+	})
+}
+
+// send 以默认优先级发送 message：启用出站队列时仅入队，否则同步写出。
+func (info *sessionInfo) send(message []byte) error {
+	return info.sendWithPriority(message, 0)
+}
+
+// sendWithPriority 以指定优先级发送 message：启用出站队列时拷贝后入队，否则忽略优先级同步写出。
+func (info *sessionInfo) sendWithPriority(message []byte, priority int) error {
+	if info.closing.Load() {
+		return ErrSessionClosing
+	}
+	if info.queue == nil {
+		return info.sendNow(message)
+	}
+	return info.queue.push(&sendItem{message: bytes.Clone(message), priority: priority})
+}
+
+// sendLatest 以 topic 发送 message：启用出站队列时拷贝后入队，并覆盖同主题尚未写出的消息；否则同步写出。
+func (info *sessionInfo) sendLatest(topic string, message []byte) error {
+	if info.closing.Load() {
+		return ErrSessionClosing
+	}
+	if info.queue == nil {
+		return info.sendNow(message)
+	}
+	if topic == "" {
+		return info.queue.push(&sendItem{message: bytes.Clone(message)})
+	}
+	return info.queue.pushLatest(&sendItem{message: bytes.Clone(message), topic: topic})
+}
+
+// sendWait 发送 message 并等待其实际写出；未启用出站队列时等同于同步写出。
+func (info *sessionInfo) sendWait(message []byte) error {
+	if info.queue == nil {
+		return info.sendNow(message)
+	}
+	item := &sendItem{message: bytes.Clone(message), done: make(chan error, 1)}
+	if err := info.queue.push(item); err != nil {
+		return err
+	}
+	return <-item.done
+}
+
+// sendFrame 以 kind 指定的帧类型发送 message：启用出站队列时拷贝后入队，否则同步写出。
+func (info *sessionInfo) sendFrame(kind FrameKind, message []byte) error {
+	if info.closing.Load() {
+		return ErrSessionClosing
+	}
+	if info.queue == nil {
+		return info.sendFrameNow(kind, message, false)
+	}
+	return info.queue.push(&sendItem{message: bytes.Clone(message), kind: kind})
+}
+
+// sendNow 在 writeLock 保护下将 message 同步写入底层 Session。
+func (info *sessionInfo) sendNow(message []byte) error {
+	return info.sendFrameNow(FrameKindDefault, message, false)
+}
+
+// sendShared 以共享缓冲区的方式发送 message，调用方保证 message 在写出前不被修改。
+//
+// 底层 Session 实现 SharedWriter 时，启用出站队列也不再拷贝 message，写出时调用 WriteShared；否则等同于 send。
+func (info *sessionInfo) sendShared(message []byte) error {
+	if _, ok := unwrapSession[SharedWriter](info.session()); !ok {
+		return info.send(message)
+	}
+	if info.closing.Load() {
+		return ErrSessionClosing
+	}
+	if info.queue == nil {
+		return info.sendFrameNow(FrameKindDefault, message, true)
+	}
+	return info.queue.push(&sendItem{message: message, shared: true})
+}
+
+// sendFrameNow 在 writeLock 保护下以 kind 指定的帧类型将 message 同步写入底层 Session，shared 语义见 writeFrameN。
+// 启用出站限流且令牌不足时不写出并返回 ErrRateLimited。
+func (info *sessionInfo) sendFrameNow(kind FrameKind, message []byte, shared bool) error {
+	info.writeLock.Lock()
+	defer info.writeLock.Unlock()
+	if !info.takeOutbound(len(message)) {
+		return ErrRateLimited
+	}
+	_, err := info.writeMessage(kind, message, shared)
+	return err
+}
+
+// sendTimeout 与 send（shared 为 true 时与 sendShared）相同，但同步写出时最多等待 timeout，超时返回包装了 ErrSendTimeout 的错误。
+//
+// 等待 writeLock 超过 timeout 时直接放弃；底层 Session 实现了 WriteDeadlineSession 时，写出本身同样以剩余时间为写超时，
+// 写出后写超时被重置为零值而不是此前的值（见 WriteDeadlineSession）。启用出站队列时入队不会阻塞，等同于 send。
+func (info *sessionInfo) sendTimeout(message []byte, shared bool, timeout time.Duration) error {
+	if info.queue != nil {
+		if shared {
+			return info.sendShared(message)
+		}
+		return info.send(message)
+	}
+	if info.closing.Load() {
+		return ErrSessionClosing
+	}
+
+	deadline := time.Now().Add(timeout)
+	if !info.writeLock.LockUntil(deadline) {
+		return ErrSendTimeout
+	}
+	defer info.writeLock.Unlock()
+	if !info.takeOutbound(len(message)) {
+		return ErrRateLimited
+	}
+	if deadlineSession, ok := unwrapSession[WriteDeadlineSession](info.session()); ok {
+		if err := deadlineSession.SetWriteDeadline(deadline); err == nil {
+			defer deadlineSession.SetWriteDeadline(time.Time{})
+		}
+	}
+	_, err := info.writeMessage(FrameKindDefault, message, shared)
+	if errors.Is(err, os.ErrDeadlineExceeded) {
+		err = fmt.Errorf("%w: %w", ErrSendTimeout, err)
+	}
+	return err
+}
+
+// awaitQueueDrained 等待出站队列中当前已入队的消息全部写出，最多等待 timeout（小于等于 0 时不限制），超时返回 ErrSendTimeout。
+func (info *sessionInfo) awaitQueueDrained(timeout time.Duration) error {
+	barrier, err := info.queue.pushBarrier()
+	if err != nil {
+		return err
+	}
+	var timeoutC <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		timeoutC = timer.C
+	}
+	select {
+	case err = <-barrier.done:
+		return err
+	case <-timeoutC:
+		return ErrSendTimeout
+	}
+}
+
+// pendingWrites 返回出站队列中尚未写出的消息数量，未启用出站队列时返回 0。
+func (info *sessionInfo) pendingWrites() int {
+	if info.queue == nil {
+		return 0
+	}
+	return info.queue.len()
+}
+
+// nextSeq 返回本会话下一个出站序号，从 1 开始单调递增，并发安全。
+func (info *sessionInfo) nextSeq() uint64 {
+	return info.outSeq.Add(1)
+}
+
+// writeMutex 是以容量为 1 的通道实现的互斥锁，在 sync.Mutex 的语义之外支持带截止时间的加锁，
+// 使 sendTimeout 在等待 writeLock 时无需轮询。零值不可用，需以 make(writeMutex, 1) 创建。
+type writeMutex chan struct{}
+
+// Lock 获取锁，锁已被持有时阻塞。
+func (m writeMutex) Lock() {
+	m <- struct{}{}
+}
+
+// Unlock 释放锁，与 sync.Mutex 相同，对未持有的锁调用会 panic。
+func (m writeMutex) Unlock() {
+	select {
+	case <-m:
+	default:
+		panic("nexus: unlock of unlocked write mutex")
+	}
+}
+
+// TryLock 尝试获取锁，锁已被持有时立即返回 false。
+func (m writeMutex) TryLock() bool {
+	select {
+	case m <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// LockUntil 在 deadline 之前获取锁，成功返回 true；到期仍未获取时返回 false。
+func (m writeMutex) LockUntil(deadline time.Time) bool {
+	if m.TryLock() {
+		return true
+	}
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+	select {
+	case m <- struct{}{}:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
+
+// takeOutbound 在启用出站限流时尝试扣减 n 字节的令牌，令牌不足时返回 false；未启用时总是返回 true，调用方需持有 writeLock。
+func (info *sessionInfo) takeOutbound(n int) bool {
+	limiter := info.outboundLimiter
+	return limiter == nil || limiter.reserve(time.Now(), float64(n)) == 0
+}
+
+// writeQueued 写出出站队列中的 item：启用出站限流时先等待令牌补足，而不是返回 ErrRateLimited；会话终止时返回 ErrSessionClosed。
+func (info *sessionInfo) writeQueued(item *sendItem) error {
+	if limiter := info.outboundLimiter; limiter != nil {
+		for {
+			info.writeLock.Lock()
+			wait := limiter.reserve(time.Now(), float64(len(item.message)))
+			info.writeLock.Unlock()
+			if wait <= 0 {
+				break
+			}
+			timer := time.NewTimer(wait)
+			select {
+			case <-timer.C:
+			case <-info.done:
+				timer.Stop()
+				return ErrSessionClosed
+			}
+		}
+	}
+
+	info.writeLock.Lock()
+	defer info.writeLock.Unlock()
+	_, err := info.writeMessage(item.kind, item.message, item.shared)
+	return err
+}
+
+// writeLoop 在独立 goroutine 中消费出站队列并逐条写出，队列关闭后退出。
+func (info *sessionInfo) writeLoop() {
+	defer close(info.writerDone)
+	queue := info.queue
+	for {
+		item, ok, closed := queue.pop()
+		if closed {
+			return
+		}
+		if !ok {
+			<-queue.notifyC
+			continue
+		}
+		if item.barrier {
+			item.finish(nil)
+			continue
+		}
+		item.finish(info.writeQueued(item))
+	}
+}
+
+// closeQueue 关闭出站队列并丢弃尚未写出的消息，写循环随之退出；未启用出站队列时无操作，可重复调用。
+func (info *sessionInfo) closeQueue() {
+	if info.queue != nil {
+		info.queue.close()
+	}
+}
+
+// writePending 按序写出关闭时从出站队列取出的消息并投递各自的结果，不经过出站限流，调用方需持有 writeLock。
+func (info *sessionInfo) writePending(items []*sendItem) {
+	for _, item := range items {
+		if item.barrier {
+			item.finish(nil)
+			continue
+		}
+		_, err := info.writeMessage(item.kind, item.message, item.shared)
+		item.finish(err)
+	}
+}
+
+// writeMessage 写出一条完整的出站消息：配置了 Options.OutboundFramer 时先编码为帧再写出，调用方需持有 writeLock。
+//
+// 分帧结果写入按会话复用的 frameBuffer，因此不再以共享缓冲区的方式写出。BufferWrite 的缓冲区与 SendStream 的数据为原始字节，不经过分帧。
+func (info *sessionInfo) writeMessage(kind FrameKind, message []byte, shared bool) (int, error) {
+	if framer := info.actor.options.OutboundFramer; framer != nil {
+		framed, err := framer.AppendFrame(info.frameBuffer[:0], message)
+		if err != nil {
+			return 0, err
+		}
+		info.frameBuffer = framed
+		message, shared = framed, false
+	}
+	return info.writeFrameN(kind, message, shared)
+}
+
+// write 将 message 写入底层 Session，调用方需持有 writeLock。
+func (info *sessionInfo) write(message []byte) error {
+	_, err := info.writeN(message)
+	return err
+}
+
+// writeN 与 write 相同，但额外返回底层 Session 实际写出的字节数，调用方需持有 writeLock。
+// 会话已进入关闭流程时不再写出并返回 ErrSessionClosing。
+func (info *sessionInfo) writeN(message []byte) (int, error) {
+	return info.writeFrameN(FrameKindDefault, message, false)
+}
+
+// writeFrameN 以 kind 指定的帧类型写出 message，kind 为 FrameKindDefault 时使用会话的默认帧类型，调用方需持有 writeLock。
+//
+// 最终帧类型不为 FrameKindDefault 且底层 Session 实现了 FrameKindWriter 时调用 WriteFrame；
+// 否则若 shared 为 true 且底层 Session 实现了 SharedWriter 则调用 WriteShared，其余情况调用 Write。
+// 底层写出的字节数少于 len(message) 却未返回错误时（如 Write 返回 (0, nil)），返回包装了 io.ErrShortWrite 的错误。
+func (info *sessionInfo) writeFrameN(kind FrameKind, message []byte, shared bool) (int, error) {
+	if info.closing.Load() {
+		return 0, ErrSessionClosing
+	}
+	if kind == FrameKindDefault {
+		kind = FrameKind(info.frameKind.Load())
+	}
+	var n int
+	var err error
+	var start time.Time
+	options := &info.actor.options
+	slowWrite := options.SlowWriteHandler != nil && options.SlowWriteThreshold > 0
+	if slowWrite {
+		start = time.Now()
+	}
+	if writer, ok := unwrapSession[FrameKindWriter](info.session()); ok && kind != FrameKindDefault {
+		n, err = writer.WriteFrame(kind, message)
+	} else if writer, ok := unwrapSession[SharedWriter](info.session()); ok && shared && kind == FrameKindDefault {
+		n, err = writer.WriteShared(message)
+	} else {
+		n, err = info.session().Write(message)
+	}
+	if slowWrite {
+		if elapsed := time.Since(start); elapsed >= options.SlowWriteThreshold {
+			options.SlowWriteHandler(info.GetSessionId(), len(message), elapsed)
+		}
+	}
+	if n > 0 {
+		info.bytesOut.Add(uint64(n))
+		info.touch()
+	}
+	if err == nil && n < len(message) {
+		// 违反 io.Writer 约定的传输层：未写完却未报告错误，按短写处理，避免消息被静默丢弃
+		err = fmt.Errorf("session write: wrote %d of %d bytes: %w", n, len(message), io.ErrShortWrite)
+	}
+	if err != nil && options.DisableReadLoop {
+		info.closeOnWriteError(err)
+	}
+	return n, err
+}
+
+// closeOnWriteError 在禁用读循环时以写出失败作为连接失效的信号，异步关闭本会话，仅首次失败生效。
+//
+// 没有读循环时无法通过读取错误感知对端断开，因此由写出错误驱动关闭；调用方可能持有 writeLock 或 sessionLock 读锁，故在新 goroutine 中完成。
+func (info *sessionInfo) closeOnWriteError(err error) {
+	if !info.writeFailed.CompareAndSwap(false, true) {
+		return
+	}
+	go info.operator.killManaged(info, ReasonWriteFailed, err.Error())
+}
+
+// flush 将写缓冲区中的数据一次性写入底层 Session 并清空缓冲区，调用方需持有 writeLock。
+func (info *sessionInfo) flush() error {
+	if len(info.writeBuffer) == 0 {
+		return nil
+	}
+	err := info.write(info.writeBuffer)
+	info.writeBuffer = info.writeBuffer[:0]
+	return err
 }
@@ -0,0 +1,80 @@
+package nexus
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// SessionKey 是会话的结构化标识（如由租户、用户与设备组成的结构体），用于在 sessionId 之外按复合键索引会话。
+//
+// SessionKey 作为 map 的键使用，必须是可比较的值（如仅包含可比较字段的结构体），不可比较的值会使会话被拒绝。
+type SessionKey = any
+
+// SessionKeyer 是 Session 的可选扩展，为会话提供比 sessionId 更丰富的结构化标识。
+//
+// 实现该接口的会话在被接管时按 SessionKey 建立索引，可通过 SessionIdByKey、SendByKey、CloseByKey 等按键寻址，
+// 避免以字符串拼接约定表达多租户等复合身份；GetSessionId 仍作为展示用的 ID 与 Actor 名称，会话替换语义仍以 sessionId 为准。
+// 多个会话返回相同的 SessionKey 时，索引指向最近接管的会话。SessionKey 返回 nil 时不建立索引。
+type SessionKeyer interface {
+	Session
+	// SessionKey 返回本会话的结构化标识，会话存续期间应保持不变。
+	SessionKey() SessionKey
+}
+
+// resolveSessionKey 返回 session 实现的 SessionKey，未实现 SessionKeyer 或返回 nil 时返回 nil；键不可比较时返回错误。
+func resolveSessionKey(session Session) (SessionKey, error) {
+	keyer, ok := unwrapSession[SessionKeyer](session)
+	if !ok {
+		return nil, nil
+	}
+	key := keyer.SessionKey()
+	if key == nil {
+		return nil, nil
+	}
+	if !reflect.TypeOf(key).Comparable() {
+		return nil, fmt.Errorf("session key of type %T is not comparable", key)
+	}
+	return key, nil
+}
+
+// SessionIdByKey 返回 SessionKey 为 key 的托管会话 ID，不存在时返回 false。
+func (o *operator) SessionIdByKey(key SessionKey) (string, bool) {
+	if key == nil || !reflect.TypeOf(key).Comparable() {
+		return "", false
+	}
+
+	o.actor.sessionLock.RLock()
+	defer o.actor.sessionLock.RUnlock()
+
+	id, ok := o.actor.keys[key]
+	return id, ok
+}
+
+// SendByKey 向 SessionKey 为 key 的会话推送 message，语义同 Send；会话不存在时返回 ErrSessionNotFound。
+func (o *operator) SendByKey(key SessionKey, message []byte) error {
+	if !o.launched.Load() {
+		return ErrNotStarted
+	}
+	id, ok := o.SessionIdByKey(key)
+	if !ok {
+		return ErrSessionNotFound
+	}
+	return o.Send(id, message)
+}
+
+// CloseByKey 优雅关闭 SessionKey 为 key 的会话，语义同 Close；不存在时无操作。
+func (o *operator) CloseByKey(key SessionKey) {
+	if id, ok := o.SessionIdByKey(key); ok {
+		o.Close(id)
+	}
+}
+
+// removeKeyIndex 将会话从 SessionKey 索引中移除，调用方需持有 sessionLock 写锁。
+func (n *Actor) removeKeyIndex(id string, info *sessionInfo) {
+	if info.key == nil {
+		return
+	}
+	if n.keys[info.key] == id {
+		delete(n.keys, info.key)
+	}
+}
@@ -0,0 +1,71 @@
+package nexus
+
+// setOwner 将 info 归属到 key 对应的所有者下，并从原所有者的索引中移除；key 为空时仅清除归属。
+//
+// 若 info 已不再被托管（已关闭或被同 id 会话替换）则无操作。
+func (o *operator) setOwner(info *sessionInfo, key string) {
+	o.actor.sessionLock.Lock()
+	defer o.actor.sessionLock.Unlock()
+
+	id := info.GetSessionId()
+	if o.actor.sessions[id] != info {
+		return
+	}
+	o.actor.removeOwnerIndex(id, info)
+	info.owner = key
+	if key == "" {
+		return
+	}
+	ids, ok := o.actor.owners[key]
+	if !ok {
+		ids = make(map[string]struct{})
+		o.actor.owners[key] = ids
+	}
+	ids[id] = struct{}{}
+}
+
+// ownerSessionIds 返回归属于 key 的所有会话 ID。
+func (o *operator) ownerSessionIds(key string) []string {
+	o.actor.sessionLock.RLock()
+	defer o.actor.sessionLock.RUnlock()
+
+	ids := o.actor.owners[key]
+	sessionIds := make([]string, 0, len(ids))
+	for id := range ids {
+		sessionIds = append(sessionIds, id)
+	}
+	return sessionIds
+}
+
+// SendToOwner 向归属于 key 的所有会话推送 message，key 不存在时无操作。
+//
+// 会话通过 SessionContext.SetOwner 设置归属，适用于同一用户多端登录等需要按用户寻址的场景。
+// errorHandler 语义同 SendTo。
+func (o *operator) SendToOwner(key string, message []byte, errorHandler ...SendErrorHandler) {
+	o.SendTo(o.ownerSessionIds(key), message, errorHandler...)
+}
+
+// CloseOwner 关闭归属于 key 的所有会话，key 不存在时无操作。并发安全。
+func (o *operator) CloseOwner(key string) {
+	o.actor.sessionLock.Lock()
+	defer o.actor.sessionLock.Unlock()
+
+	for id := range o.actor.owners[key] {
+		if info, ok := o.actor.sessions[id]; ok {
+			o.actorContext.Kill(info.ref, false, o.actor.options.formatReason(ReasonCloseOwner, ""))
+		}
+	}
+}
+
+// removeOwnerIndex 将会话从其所有者索引中移除，调用方需持有 sessionLock 写锁。
+func (n *Actor) removeOwnerIndex(id string, info *sessionInfo) {
+	if info.owner == "" {
+		return
+	}
+	if ids, ok := n.owners[info.owner]; ok {
+		delete(ids, id)
+		if len(ids) == 0 {
+			delete(n.owners, info.owner)
+		}
+	}
+}
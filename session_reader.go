@@ -17,6 +17,10 @@ import (
 //
 // 返回值约定：n 为读到的字节数且 0 <= n <= len(data)；data 为 nil 当且仅当 n == 0；
 // 遇 EOF 时应先返回已读数据（n > 0, err == nil），下次 Read 再返回 (0, nil, io.EOF)。
+// 非阻塞实现在暂无数据时应返回 (0, nil, ErrNoDataYet)，框架会等待 Options.ReadRetryInterval 后重试；
+// (0, nil, nil) 按同样的语义处理，不会被作为空消息投递。
+//
+// 实现同时实现 io.Closer 时，Nexus 会在会话结束、所有读取停止且不再有消息处理使用返回的 data 后调用 Close，可用于归还缓冲区。
 type SessionReader interface {
 	Read() (n int, data []byte, err error)
 }
@@ -25,6 +29,9 @@ type SessionReader interface {
 //
 // 要求实现线程安全；Provide 在 sessionActor 的 Prelaunch 阶段调用。
 // 返回的 SessionReader 不可为 nil（框架会校验并返回错误）。
+//
+// Provide 对每个会话单独调用，可根据 session（如其具体类型、元数据）为不同会话返回不同类型的 SessionReader，
+// 以便在同一个 Nexus 上承载多种协议；业务可通过 SessionContext.GetSessionReader 获取本会话实际使用的 Reader。
 type SessionReaderProvider interface {
 	Provide(session Session) (SessionReader, error)
 }
@@ -65,8 +72,10 @@ func newDefaultSessionReader(session Session) (SessionReader, error) {
 type defaultSessionReader struct {
 	session    Session
 	mu         sync.Mutex
-	buf        []byte // 复用缓冲区；Read 返回的 data 为 buf 的切片，仅在下一次 Read 前有效
-	pendingErr error  // 与最后一次读同批的 EOF，下次 Read 时返回
+	buf        []byte        // 复用缓冲区；Read 返回的 data 为 buf 的切片，仅在下一次 Read 前有效
+	pendingErr error         // 与最后一次读同批的 EOF，下次 Read 时返回
+	getBuffer  func() []byte // Options.ReadBufferGet，为 nil 时自行分配 buf
+	putBuffer  func([]byte)  // Options.ReadBufferPut，Close 时归还 buf，可为 nil
 }
 
 // Read 从 Session 读入内部缓冲区并返回本批数据的长度与切片。
@@ -93,14 +102,56 @@ func (r *defaultSessionReader) Read() (n int, data []byte, err error) {
 		return 0, nil, err
 	}
 
-	if cap(r.buf) < defaultReadBufferSize {
+	if r.buf == nil && r.getBuffer != nil {
+		r.buf = r.getBuffer()
+	}
+	if cap(r.buf) == 0 {
 		r.buf = make([]byte, defaultReadBufferSize)
 	}
 
-	n, err = r.session.Read(r.buf)
+	n, err = r.session.Read(r.buf[:cap(r.buf)])
 	if n > 0 {
 		data = r.buf[:n:n]
 	}
 
 	return n, data, err
 }
+
+// bindReadBuffer 将 Options.ReadBufferGet 与 ReadBufferPut 交给 reader 中的默认 SessionReader；reader 实现了 Unwrap() SessionReader 时
+// （如 NewTimeoutReaderProvider 的包装）逐层查找。未配置 ReadBufferGet 或已绑定时返回 true，reader 中不含默认 SessionReader 时返回 false。
+func (o *Options) bindReadBuffer(reader SessionReader) bool {
+	if o.ReadBufferGet == nil {
+		return true
+	}
+	for reader != nil {
+		if defaultReader, ok := reader.(*defaultSessionReader); ok {
+			defaultReader.getBuffer, defaultReader.putBuffer = o.ReadBufferGet, o.ReadBufferPut
+			return true
+		}
+		wrapper, ok := reader.(interface{ Unwrap() SessionReader })
+		if !ok {
+			break
+		}
+		reader = wrapper.Unwrap()
+	}
+	return false
+}
+
+// warnReadBufferUnused 在配置了 Options.ReadBufferGet、而会话的 SessionReader 中不含默认 SessionReader 时记录一次警告。
+func (n *Actor) warnReadBufferUnused() {
+	n.readBufferWarn.Do(func() {
+		n.actorContext.Logger().Warn("read buffer provider ignored, session reader does not wrap the default reader")
+	})
+}
+
+// Close 在配置了 Options.ReadBufferPut 时归还从 ReadBufferGet 获取的缓冲区，由 Nexus 在会话结束且读取停止后调用。
+func (r *defaultSessionReader) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.buf != nil && r.getBuffer != nil && r.putBuffer != nil {
+		r.putBuffer(r.buf)
+	}
+	r.buf = nil
+	return nil
+}
@@ -0,0 +1,45 @@
+package nexus
+
+import (
+	"bufio"
+	"sync"
+)
+
+// NewBufferedReaderProvider 返回以 bufio.Reader 包装 Session 的 SessionReaderProvider，size 为缓冲区大小，小于等于 0 时使用 bufio 的默认值。
+//
+// 适用于 Session.Read 对应大量小系统调用的原始套接字协议：每次 Read 尽可能多地从底层读入缓冲区，并返回当前缓冲的全部数据。
+// 返回的 data 直接指向 bufio 的内部缓冲区而不拷贝，生命周期遵循 SessionReader 约定，仅在下一次 Read 前有效。
+func NewBufferedReaderProvider(size int) SessionReaderProvider {
+	return SessionReaderProviderFN(func(session Session) (SessionReader, error) {
+		var reader *bufio.Reader
+		if size > 0 {
+			reader = bufio.NewReaderSize(session, size)
+		} else {
+			reader = bufio.NewReader(session)
+		}
+		return &bufferedSessionReader{reader: reader}, nil
+	})
+}
+
+// bufferedSessionReader 是 NewBufferedReaderProvider 提供的 SessionReader，线程安全。
+type bufferedSessionReader struct {
+	mu     sync.Mutex
+	reader *bufio.Reader
+}
+
+// Read 返回当前缓冲的全部数据，缓冲区为空时阻塞读取底层 Session 直到有数据或出错。
+//
+// 通过 Peek 取得缓冲区视图后立即 Discard，数据在下一次填充缓冲区（即下一次 Read）前不会被覆盖。
+func (r *bufferedSessionReader) Read() (n int, data []byte, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.reader.Buffered() == 0 {
+		if _, err = r.reader.Peek(1); err != nil && r.reader.Buffered() == 0 {
+			return 0, nil, err
+		}
+	}
+	data, _ = r.reader.Peek(r.reader.Buffered())
+	n, _ = r.reader.Discard(len(data))
+	return n, data[:n:n], nil
+}
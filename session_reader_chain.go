@@ -0,0 +1,199 @@
+package nexus
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// SessionReaderStage 是读取管线中的一级，将上一级的字节流 r 包装为新的字节流（如解密、解压），用于 ChainReaders。
+//
+// 第一级收到的 r 为底层 Session，每一级只能从 r 读取；构造失败时返回 error。
+// 若某一级返回的 io.Reader 同时实现了 FrameReader，则它被视为分帧级，ChainReaders 以它产出的完整帧作为消息边界。
+type SessionReaderStage func(r io.Reader) (io.Reader, error)
+
+// FrameReader 是分帧级 io.Reader 的可选扩展，每次 ReadFrame 返回一条完整消息。
+//
+// 返回的 frame 为实现方内部缓冲区的视图，仅在下一次 ReadFrame 或 Read 前有效，所有权约定与 SessionReader 一致。
+type FrameReader interface {
+	io.Reader
+	// ReadFrame 返回下一条完整消息，在帧边界遇到 EOF 时返回 (nil, io.EOF)。
+	ReadFrame() (frame []byte, err error)
+}
+
+// ChainReaders 将 stages 依次叠加在 session 之上，组合为一个 SessionReader，适用于解密 → 解压 → 分帧等分层协议。
+//
+// 数据自 session 起依次流经各级：stages[0] 读取 session，stages[i] 读取 stages[i-1] 的输出，返回的 SessionReader 读取最后一级。
+// 最后一级实现 FrameReader（如 NewLengthPrefixStage、NewDelimiterStage）时，每次 Read 返回一条完整消息；否则每次 Read 返回
+// 最后一级单次 Read 的结果，边界不固定。
+//
+// 缓冲区所有权：各级之间通过 io.Reader 传递数据，上一级将数据拷贝进下一级提供的缓冲区，因此每一级只需管理自己的内部缓冲区，
+// 不会看到其他级的存储；返回的 data 为最后一级（分帧级）或 ChainReaders 内部复用缓冲区的视图，生命周期遵循 SessionReader 约定。
+// 分帧级应位于管线末尾，位于其后的级只能看到去除了边界的字节流。任一 stage 为 nil 或构造失败时返回 error。
+func ChainReaders(session Session, stages ...SessionReaderStage) (SessionReader, error) {
+	if session == nil {
+		return nil, errors.New("chain readers: session is nil")
+	}
+	var r io.Reader = session
+	for i, stage := range stages {
+		if stage == nil {
+			return nil, fmt.Errorf("chain readers: stage %d is nil", i)
+		}
+		next, err := stage(r)
+		if err != nil {
+			return nil, fmt.Errorf("chain readers: stage %d: %w", i, err)
+		}
+		if next == nil {
+			return nil, fmt.Errorf("chain readers: stage %d returned nil reader", i)
+		}
+		r = next
+	}
+	return &chainReader{source: r}, nil
+}
+
+// chainReader 是 ChainReaders 返回的 SessionReader，线程安全。
+type chainReader struct {
+	source io.Reader
+	mu     sync.Mutex
+	buf    []byte // 最后一级不是分帧级时的复用缓冲区
+}
+
+// Read 返回最后一级产出的下一条消息或下一段数据。
+func (r *chainReader) Read() (n int, data []byte, err error) {
+	const defaultChainBufferSize = 4096
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if frameReader, ok := r.source.(FrameReader); ok {
+		frame, err := frameReader.ReadFrame()
+		if len(frame) == 0 {
+			return 0, nil, err
+		}
+		return len(frame), frame[:len(frame):len(frame)], err
+	}
+
+	if cap(r.buf) < defaultChainBufferSize {
+		r.buf = make([]byte, defaultChainBufferSize)
+	}
+	n, err = r.source.Read(r.buf[:cap(r.buf)])
+	if n > 0 {
+		data = r.buf[:n:n]
+	}
+	return n, data, err
+}
+
+// NewLengthPrefixStage 返回按 size 字节长度前缀（按 order 解码，仅包含消息体长度）分帧的 SessionReaderStage，与 NewLengthPrefixFramer 对称。
+//
+// size 取值为 1、2、4 或 8；产出的每条消息不包含长度前缀。消息体长度超出 maxBody 时返回 ErrFrameTooLarge，maxBody 小于等于 0 时不限制。
+func NewLengthPrefixStage(size int, order binary.ByteOrder, maxBody int) SessionReaderStage {
+	return func(r io.Reader) (io.Reader, error) {
+		if size != 1 && size != 2 && size != 4 && size != 8 {
+			return nil, fmt.Errorf("length prefix stage: invalid prefix size %d", size)
+		}
+		if order == nil {
+			return nil, errors.New("length prefix stage: byte order is nil")
+		}
+		reader := &headerBodyReader{
+			source:    r,
+			headerLen: size,
+			bodyLenFn: func(header []byte) (int, error) {
+				var length uint64
+				switch size {
+				case 1:
+					length = uint64(header[0])
+				case 2:
+					length = uint64(order.Uint16(header))
+				case 4:
+					length = uint64(order.Uint32(header))
+				case 8:
+					length = order.Uint64(header)
+				}
+				if length > uint64(int(^uint(0)>>1)-size) {
+					return 0, fmt.Errorf("length prefix stage: body length %d: %w", length, ErrFrameTooLarge)
+				}
+				return int(length), nil
+			},
+			maxBody: maxBody,
+		}
+		return &frameStage{next: func() ([]byte, error) {
+			_, frame, err := reader.Read()
+			if err != nil {
+				return nil, err
+			}
+			return frame[size:], nil
+		}}, nil
+	}
+}
+
+// NewDelimiterStage 返回以 delimiter 结尾分帧的 SessionReaderStage，与 NewDelimiterFramer 对称。
+//
+// 产出的每条消息不包含 delimiter；流结束时末尾不以 delimiter 结尾的剩余数据作为最后一条消息返回。
+// 单条消息（含 delimiter）超出 maxFrame 时返回 ErrFrameTooLarge，maxFrame 小于等于 0 时使用 bufio.MaxScanTokenSize。
+func NewDelimiterStage(delimiter []byte, maxFrame int) SessionReaderStage {
+	delimiter = bytes.Clone(delimiter)
+	return func(r io.Reader) (io.Reader, error) {
+		if len(delimiter) == 0 {
+			return nil, errors.New("delimiter stage: delimiter is empty")
+		}
+		limit := maxFrame
+		if limit <= 0 {
+			limit = bufio.MaxScanTokenSize
+		}
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(nil, limit)
+		scanner.Split(func(data []byte, atEOF bool) (advance int, token []byte, err error) {
+			if i := bytes.Index(data, delimiter); i >= 0 {
+				return i + len(delimiter), data[:i], nil
+			}
+			if atEOF && len(data) > 0 {
+				return len(data), data, nil
+			}
+			return 0, nil, nil
+		})
+		return &frameStage{next: func() ([]byte, error) {
+			if scanner.Scan() {
+				return scanner.Bytes(), nil
+			}
+			if err := scanner.Err(); err != nil {
+				if errors.Is(err, bufio.ErrTooLong) {
+					return nil, fmt.Errorf("delimiter stage: %w: %w", err, ErrFrameTooLarge)
+				}
+				return nil, err
+			}
+			return nil, io.EOF
+		}}, nil
+	}
+}
+
+// frameStage 将逐帧读取函数适配为 FrameReader：作为 io.Reader 使用时，一帧未被读完的部分在后续 Read 中继续返回。
+type frameStage struct {
+	next    func() ([]byte, error)
+	pending []byte // 当前帧中尚未被 Read 读走的部分
+}
+
+// Read 将当前帧的剩余部分拷贝到 p，当前帧读完后读取下一帧。
+func (s *frameStage) Read(p []byte) (n int, err error) {
+	for len(s.pending) == 0 {
+		if s.pending, err = s.next(); err != nil {
+			s.pending = nil
+			return 0, err
+		}
+	}
+	n = copy(p, s.pending)
+	s.pending = s.pending[n:]
+	return n, nil
+}
+
+// ReadFrame 返回下一条完整消息；此前通过 Read 读了一部分的帧会先返回其剩余部分。
+func (s *frameStage) ReadFrame() (frame []byte, err error) {
+	if len(s.pending) > 0 {
+		frame, s.pending = s.pending, nil
+		return frame, nil
+	}
+	return s.next()
+}
@@ -0,0 +1,89 @@
+package nexus
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// NewHeaderBodyReader 返回按“定长头部 + 变长消息体”分帧的 SessionReader，每次 Read 返回完整的一帧（头部 + 消息体）。
+//
+// 参数：
+//   - session：读取的数据来源。
+//   - headerLen：头部固定长度，必须大于 0。
+//   - bodyLenFn：根据头部解析消息体长度（如从头部中取出类型标签与长度字段），返回 error 时本次 Read 失败。
+//   - maxBody：消息体最大长度，超出时返回 ErrFrameTooLarge；小于等于 0 时不限制。
+//
+// 底层 Session 的单次 Read 可能只返回部分头部或消息体，实现会持续读取直到凑齐完整帧；
+// 在帧边界遇到 EOF 时返回 (0, nil, io.EOF)，帧中途遇到 EOF 时返回 io.ErrUnexpectedEOF。
+// 返回的 data 复用内部缓冲区，生命周期遵循 SessionReader 约定。
+// 与 SessionReaderProviderFN 配合使用：return nexus.NewHeaderBodyReader(session, 8, parseHeader, 1<<20), nil。
+func NewHeaderBodyReader(session Session, headerLen int, bodyLenFn func(header []byte) (int, error), maxBody int) SessionReader {
+	return &headerBodyReader{
+		source:    session,
+		headerLen: headerLen,
+		bodyLenFn: bodyLenFn,
+		maxBody:   maxBody,
+	}
+}
+
+// headerBodyReader 是 NewHeaderBodyReader 的实现，复用内部缓冲区，线程安全。
+type headerBodyReader struct {
+	source    io.Reader
+	headerLen int
+	bodyLenFn func(header []byte) (int, error)
+	maxBody   int
+	mu        sync.Mutex
+	buf       []byte // 复用缓冲区；Read 返回的 data 为 buf 的切片，仅在下一次 Read 前有效
+}
+
+// Read 读取并返回下一帧，data 包含头部与消息体。
+func (r *headerBodyReader) Read() (n int, data []byte, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.source == nil {
+		return 0, nil, io.ErrClosedPipe
+	}
+	if r.headerLen <= 0 {
+		return 0, nil, fmt.Errorf("header body reader: invalid header length %d", r.headerLen)
+	}
+	if r.bodyLenFn == nil {
+		return 0, nil, errors.New("header body reader: body length function is nil")
+	}
+
+	if cap(r.buf) < r.headerLen {
+		r.buf = make([]byte, r.headerLen)
+	}
+	header := r.buf[:r.headerLen]
+	if _, err = io.ReadFull(r.source, header); err != nil {
+		return 0, nil, err
+	}
+
+	bodyLen, err := r.bodyLenFn(header)
+	if err != nil {
+		return 0, nil, err
+	}
+	if bodyLen < 0 {
+		return 0, nil, fmt.Errorf("header body reader: invalid body length %d", bodyLen)
+	}
+	if r.maxBody > 0 && bodyLen > r.maxBody {
+		return 0, nil, fmt.Errorf("header body reader: body length %d exceeds %d: %w", bodyLen, r.maxBody, ErrFrameTooLarge)
+	}
+
+	frameLen := r.headerLen + bodyLen
+	if cap(r.buf) < frameLen {
+		buf := make([]byte, frameLen)
+		copy(buf, header)
+		r.buf = buf
+	}
+	frame := r.buf[:frameLen:frameLen]
+	if _, err = io.ReadFull(r.source, frame[r.headerLen:]); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return 0, nil, err
+	}
+	return frameLen, frame, nil
+}
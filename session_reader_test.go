@@ -0,0 +1,90 @@
+package nexus_test
+
+import (
+	"encoding/binary"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	nexus "github.com/kercylan98/vivid-nexus"
+	"github.com/kercylan98/vivid-nexus/nexustest"
+)
+
+// TestPerSessionReader 验证 SessionReaderProvider 可按会话返回不同的 SessionReader，使同一个 Nexus 同时承载多种协议。
+func TestPerSessionReader(t *testing.T) {
+	var lock sync.Mutex
+	received := make(map[string]string)
+	readers := make(map[string]nexus.SessionReader)
+	n := newTestNexus(t, provide(&testActor{
+		connected: func(ctx nexus.SessionContext) {
+			lock.Lock()
+			readers[ctx.GetSessionId()] = ctx.GetSessionReader()
+			lock.Unlock()
+		},
+		message: func(ctx nexus.SessionContext, message []byte) {
+			lock.Lock()
+			received[ctx.GetSessionId()] = string(message)
+			lock.Unlock()
+		},
+	}), nexus.WithSessionReaderProvider(nexus.SessionReaderProviderFN(func(session nexus.Session) (nexus.SessionReader, error) {
+		if session.(nexus.MetadataSession).Metadata()["protocol"] == "text" {
+			return nexus.ChainReaders(session, nexus.NewDelimiterStage([]byte("\n"), 1024))
+		}
+		return nexus.ChainReaders(session, nexus.NewLengthPrefixStage(2, binary.BigEndian, 1024))
+	})))
+
+	for _, tc := range []struct {
+		protocol string
+		data     []byte
+		want     string
+	}{
+		{protocol: "text", data: []byte("hello\n"), want: "hello"},
+		{protocol: "binary", data: []byte{0, 5, 'w', 'o', 'r', 'l', 'd'}, want: "world"},
+	} {
+		memory := nexustest.NewMemorySession(tc.protocol, map[string]any{"protocol": tc.protocol})
+		takeover(t, n, memory)
+		if err := memory.Feed(tc.data); err != nil {
+			t.Fatalf("feed %s: %v", tc.protocol, err)
+		}
+	}
+
+	eventually(t, "messages of both protocols", func() bool {
+		lock.Lock()
+		defer lock.Unlock()
+		return received["text"] == "hello" && received["binary"] == "world"
+	})
+	lock.Lock()
+	defer lock.Unlock()
+	if readers["text"] == nil || readers["text"] == readers["binary"] {
+		t.Fatalf("sessions share reader %v, want distinct readers", readers["text"])
+	}
+}
+
+// TestReadBufferProviderThroughTimeoutReader 验证 WithReadBufferProvider 对经 NewTimeoutReaderProvider 包装的默认 SessionReader 同样生效。
+func TestReadBufferProviderThroughTimeoutReader(t *testing.T) {
+	var gets, puts atomic.Int32
+	var messages atomic.Int32
+	provider := nexus.NewTimeoutReaderProvider(nexus.NewOptions().SessionReaderProvider, time.Minute)
+	n := newTestNexus(t, provide(&testActor{message: func(ctx nexus.SessionContext, message []byte) { messages.Add(1) }}),
+		nexus.WithSessionReaderProvider(provider),
+		nexus.WithReadBufferProvider(func() []byte {
+			gets.Add(1)
+			return make([]byte, 128)
+		}, func(buf []byte) {
+			puts.Add(1)
+		}),
+	)
+
+	memory := nexustest.NewMemorySession("pooled", nil)
+	takeover(t, n, memory)
+	if err := memory.Feed([]byte("hello")); err != nil {
+		t.Fatalf("feed: %v", err)
+	}
+	eventually(t, "message", func() bool { return messages.Load() == 1 })
+	n.Close("pooled")
+	eventually(t, "buffer returned", func() bool { return puts.Load() == 1 })
+	if got := gets.Load(); got != 1 {
+		t.Fatalf("ReadBufferGet called %d times, want 1", got)
+	}
+}
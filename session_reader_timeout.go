@@ -0,0 +1,98 @@
+package nexus
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// NewTimeoutReaderProvider 包装 inner，为其提供的每个 SessionReader 的单次 Read 施加 timeout 超时，适用于无法设置读截止时间的传输层。
+//
+// 每次 Read 在独立 goroutine 中调用内部 Reader，超过 timeout 未返回时放弃等待并返回 ErrReadTimeout，读循环据此以 ReasonReadFailed 关闭会话。
+// 被放弃的读取无法中断，其 goroutine 会一直存活到底层读取返回（通常在会话关闭、底层连接被关闭之后），期间持有内部 Reader 的缓冲区；
+// 因此该方式以每个超时会话短暂多占用一个 goroutine 为代价，传输层支持时应优先使用读截止时间（如 net.Conn.SetReadDeadline）。
+// 超时后再次调用 Read 不会并发调用内部 Reader，而是继续等待被放弃的那次读取。timeout 小于等于 0 时直接返回 inner。
+// 包装后的 Reader 始终实现 io.Closer：会话结束时等待被放弃的读取返回后，再关闭实现了 io.Closer 的内部 Reader。
+func NewTimeoutReaderProvider(inner SessionReaderProvider, timeout time.Duration) SessionReaderProvider {
+	if timeout <= 0 {
+		return inner
+	}
+	return SessionReaderProviderFN(func(session Session) (SessionReader, error) {
+		if inner == nil {
+			return nil, errors.New("timeout reader provider: inner provider is nil")
+		}
+		reader, err := inner.Provide(session)
+		if err != nil || reader == nil {
+			return reader, err
+		}
+		return &timeoutReader{reader: reader, timeout: timeout}, nil
+	})
+}
+
+// Unwrap 返回被包装的内部 Reader，使 WithReadBufferProvider 能够作用于其中的默认 SessionReader。
+func (r *timeoutReader) Unwrap() SessionReader {
+	return r.reader
+}
+
+// timeoutReadResult 是一次内部 Read 的结果。
+type timeoutReadResult struct {
+	n    int
+	data []byte
+	err  error
+}
+
+// timeoutReader 是 NewTimeoutReaderProvider 的实现，同一时刻至多有一次内部 Read 在进行。
+type timeoutReader struct {
+	reader  SessionReader
+	timeout time.Duration
+	mu      sync.Mutex
+	pending chan timeoutReadResult // 进行中的内部 Read 的结果通道，超时后保留以便下次 Read 继续等待，容量为 1
+}
+
+// Read 返回内部 Reader 下一次 Read 的结果，超过 timeout 未返回时返回 ErrReadTimeout。
+func (r *timeoutReader) Read() (n int, data []byte, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.pending == nil {
+		pending := make(chan timeoutReadResult, 1)
+		r.pending = pending
+		go func() {
+			var result timeoutReadResult
+			defer func() {
+				if recovered := recover(); recovered != nil {
+					result = timeoutReadResult{err: fmt.Errorf("session read panic: %v", recovered)}
+				}
+				pending <- result
+			}()
+			result.n, result.data, result.err = r.reader.Read()
+		}()
+	}
+
+	timer := time.NewTimer(r.timeout)
+	defer timer.Stop()
+	select {
+	case result := <-r.pending:
+		r.pending = nil
+		return result.n, result.data, result.err
+	case <-timer.C:
+		return 0, nil, ErrReadTimeout
+	}
+}
+
+// Close 等待被放弃的内部 Read 返回后，关闭实现了 io.Closer 的内部 Reader。
+func (r *timeoutReader) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.pending != nil {
+		<-r.pending
+		r.pending = nil
+	}
+	if closer, ok := r.reader.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
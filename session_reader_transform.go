@@ -0,0 +1,83 @@
+package nexus
+
+import (
+	"errors"
+	"sync"
+)
+
+// SessionTransform 将一段原始入站数据 src 变换（如解密）后追加到 dst 并返回结果，返回值语义同 append。
+//
+// dst 为实现方可复用的缓冲区（长度为 0），实现应尽量追加到 dst 而非另行分配，以保持缓冲区复用；src 仅在本次调用内有效。
+// 每次调用收到的 src 是底层 Session 单次 Read 的结果，边界不固定，因此变换应适用于任意切分的字节流（如流式密码）。
+type SessionTransform func(dst, src []byte) ([]byte, error)
+
+// NewTransformReaderProvider 包装 inner，使其提供的 SessionReader 读取经 transform 变换后的字节流，而非底层 Session 的原始数据。
+//
+// 变换位于原始 Session 与分帧逻辑之间：inner 收到的是包装后的 Session，其 Read 返回变换后的数据，GetSessionId、Write、Close
+// 等其余方法委托给原 Session，可通过 Unwrap 取回原 Session。因此任意分帧 SessionReader（如 NewHeaderBodyReader）都可以叠加在
+// 解密等变换之上。原始数据与变换结果均使用按会话复用的缓冲区；transform 为 nil 时直接返回 inner。
+func NewTransformReaderProvider(inner SessionReaderProvider, transform SessionTransform) SessionReaderProvider {
+	if transform == nil {
+		return inner
+	}
+	return SessionReaderProviderFN(func(session Session) (SessionReader, error) {
+		if inner == nil {
+			return nil, errors.New("transform reader provider: inner provider is nil")
+		}
+		return inner.Provide(&transformSession{Session: session, transform: transform})
+	})
+}
+
+// transformSession 在 Read 时对底层 Session 的数据执行 transform，其余方法委托给原 Session。
+type transformSession struct {
+	Session
+	transform SessionTransform
+	mu        sync.Mutex
+	raw       []byte // 复用的原始数据缓冲区
+	out       []byte // 复用的变换结果缓冲区
+	pending   []byte // out 中尚未被读走的部分
+	err       error  // 与最后一批数据同时返回的读错误，pending 读完后返回
+}
+
+// Read 返回变换后的数据；上一批变换结果未读完时优先返回剩余部分。
+func (s *transformSession) Read(p []byte) (n int, err error) {
+	const defaultTransformBufferSize = 4096
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for len(s.pending) == 0 {
+		if s.err != nil {
+			return 0, s.err
+		}
+		if cap(s.raw) < defaultTransformBufferSize {
+			s.raw = make([]byte, defaultTransformBufferSize)
+		}
+		rawN, readErr := s.Session.Read(s.raw[:cap(s.raw)])
+		if rawN > 0 {
+			out, transformErr := s.transform(s.out[:0], s.raw[:rawN])
+			if transformErr != nil {
+				return 0, transformErr
+			}
+			s.out = out
+			s.pending = out
+		}
+		if readErr != nil {
+			// 本批已有数据时，读错误（如 EOF）留待数据读完后返回
+			s.err = readErr
+			continue
+		}
+		if rawN == 0 {
+			return 0, nil
+		}
+	}
+
+	n = copy(p, s.pending)
+	s.pending = s.pending[n:]
+	return n, nil
+}
+
+// Unwrap 返回被包装的原 Session。
+func (s *transformSession) Unwrap() Session {
+	return s.Session
+}
@@ -0,0 +1,116 @@
+package nexus
+
+import (
+	"maps"
+	"path"
+	"slices"
+)
+
+// joinRoom 将 info 加入 room，重复加入无操作；若 info 已不再被托管则无操作。
+func (o *operator) joinRoom(info *sessionInfo, room string) {
+	o.actor.sessionLock.Lock()
+	defer o.actor.sessionLock.Unlock()
+
+	id := info.GetSessionId()
+	if o.actor.sessions[id] != info {
+		return
+	}
+	if info.rooms == nil {
+		info.rooms = make(map[string]struct{})
+	}
+	info.rooms[room] = struct{}{}
+	ids, ok := o.actor.rooms[room]
+	if !ok {
+		ids = make(map[string]struct{})
+		o.actor.rooms[room] = ids
+	}
+	ids[id] = struct{}{}
+}
+
+// leaveRoom 将 info 移出 room，未加入时无操作；若 info 已不再被托管（如已被同 ID 的新会话替换）则无操作，避免移除新会话的成员关系。
+func (o *operator) leaveRoom(info *sessionInfo, room string) {
+	o.actor.sessionLock.Lock()
+	defer o.actor.sessionLock.Unlock()
+
+	if o.actor.sessions[info.GetSessionId()] != info {
+		return
+	}
+	if _, ok := info.rooms[room]; !ok {
+		return
+	}
+	delete(info.rooms, room)
+	o.actor.removeRoomMember(room, info.GetSessionId())
+}
+
+// roomSessionIds 返回所有满足 match 的房间成员的并集（已去重）。
+func (o *operator) roomSessionIds(match func(room string) bool) []string {
+	o.actor.sessionLock.RLock()
+	defer o.actor.sessionLock.RUnlock()
+
+	var sessionIds []string
+	var seen = make(map[string]struct{})
+	for room, ids := range o.actor.rooms {
+		if !match(room) {
+			continue
+		}
+		for id := range ids {
+			if _, ok := seen[id]; ok {
+				continue
+			}
+			seen[id] = struct{}{}
+			sessionIds = append(sessionIds, id)
+		}
+	}
+	return sessionIds
+}
+
+// BroadcastRoom 向 room 中的所有会话推送 message，房间不存在时无操作。errorHandler 语义同 SendTo。
+func (o *operator) BroadcastRoom(room string, message []byte, errorHandler ...SendErrorHandler) {
+	o.sendTo(o.roomSessionIds(func(name string) bool {
+		return name == room
+	}), message, errorHandler)
+}
+
+// BroadcastRoomPattern 向名称匹配 pattern 的所有房间中的会话推送 message，同时属于多个房间的会话只发送一次。
+//
+// pattern 采用 path.Match 语法：例如 "game:*" 匹配 "game:lobby"、"region:*:lobby" 匹配 "region:us:lobby"；
+// 注意 "*" 不会跨越 "/"。pattern 非法时不匹配任何房间。errorHandler 语义同 SendTo。
+func (o *operator) BroadcastRoomPattern(pattern string, message []byte, errorHandler ...SendErrorHandler) {
+	o.sendTo(o.roomSessionIds(func(room string) bool {
+		matched, err := path.Match(pattern, room)
+		return err == nil && matched
+	}), message, errorHandler)
+}
+
+// RoomSize 返回 room 中当前的会话数量，房间不存在时返回 0。
+func (o *operator) RoomSize(room string) int {
+	o.actor.sessionLock.RLock()
+	defer o.actor.sessionLock.RUnlock()
+
+	return len(o.actor.rooms[room])
+}
+
+// Rooms 返回当前至少有一个会话的所有房间名称，顺序不固定。
+func (o *operator) Rooms() []string {
+	o.actor.sessionLock.RLock()
+	defer o.actor.sessionLock.RUnlock()
+
+	return slices.Collect(maps.Keys(o.actor.rooms))
+}
+
+// removeRoomMember 将 id 从 room 的成员中移除，房间为空时一并删除，调用方需持有 sessionLock 写锁。
+func (n *Actor) removeRoomMember(room, id string) {
+	if ids, ok := n.rooms[room]; ok {
+		delete(ids, id)
+		if len(ids) == 0 {
+			delete(n.rooms, room)
+		}
+	}
+}
+
+// removeRoomIndex 将会话从其加入的所有房间中移除，调用方需持有 sessionLock 写锁。
+func (n *Actor) removeRoomIndex(id string, info *sessionInfo) {
+	for room := range info.rooms {
+		n.removeRoomMember(room, id)
+	}
+}
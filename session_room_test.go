@@ -0,0 +1,39 @@
+package nexus_test
+
+import (
+	"sync/atomic"
+	"testing"
+
+	nexus "github.com/kercylan98/vivid-nexus"
+	"github.com/kercylan98/vivid-nexus/nexustest"
+)
+
+// TestLeaveRoomAfterReplaced 验证被替换的旧会话在 OnDisconnected 中退出房间时，不会移除同 ID 新会话的成员关系。
+func TestLeaveRoomAfterReplaced(t *testing.T) {
+	release := make(chan struct{})
+	var replaced atomic.Bool
+	n := newTestNexus(t, provide(&testActor{
+		connected: func(ctx nexus.SessionContext) { ctx.JoinRoom("lobby") },
+		disconnected: func(ctx nexus.SessionContext) {
+			if replaced.Load() {
+				<-release
+			}
+			ctx.LeaveRoom("lobby")
+		},
+	}))
+
+	first := nexustest.NewMemorySession("player", nil)
+	takeover(t, n, first)
+	replaced.Store(true)
+	second := nexustest.NewMemorySession("player", nil)
+	takeover(t, n, second)
+
+	// 新会话已加入房间后，再让旧会话退出房间
+	close(release)
+	eventually(t, "replaced session closed", first.Closed)
+
+	n.(nexus.GroupNexus).BroadcastRoom("lobby", []byte("hello"))
+	if written := second.Written(); len(written) != 1 || string(written[0]) != "hello" {
+		t.Fatalf("new session written %q, want [hello]", written)
+	}
+}
@@ -0,0 +1,210 @@
+package nexus
+
+import (
+	"container/heap"
+	"math"
+	"sync"
+)
+
+// sendItem 是出站队列中的一条待写出消息。
+type sendItem struct {
+	message  []byte
+	kind     FrameKind  // 帧类型，FrameKindDefault 表示写出时使用会话的默认帧类型
+	shared   bool       // message 为调用方共享的只读缓冲区（未拷贝），写出时交由 SharedWriter
+	priority int        // 优先级，数值越大越先写出
+	seq      uint64     // 入队序号，用于保证同优先级消息 FIFO
+	barrier  bool       // 屏障项，不写出任何数据，出队即表示此前入队的消息均已写出
+	topic    string     // SendLatest 的主题，非空时同主题尚未写出的消息会被新消息覆盖
+	done     chan error // 可选，写出完成或被丢弃时投递结果，容量为 1
+}
+
+// finish 在 item 带有 done 时投递写出结果。
+func (item *sendItem) finish(err error) {
+	if item.done != nil {
+		item.done <- err
+	}
+}
+
+// sendHeap 是按优先级从高到低、同优先级按入队顺序出队的堆。
+type sendHeap []*sendItem
+
+func (h sendHeap) Len() int { return len(h) }
+
+func (h sendHeap) Less(i, j int) bool {
+	if h[i].priority != h[j].priority {
+		return h[i].priority > h[j].priority
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h sendHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *sendHeap) Push(x any) { *h = append(*h, x.(*sendItem)) }
+
+func (h *sendHeap) Pop() any {
+	old := *h
+	item := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return item
+}
+
+// sendQueue 是单个会话的出站优先级队列，由 sessionActor 的写循环消费。
+//
+// 排序保证：优先级高的消息先于已排队的低优先级消息写出；同优先级消息严格按入队顺序写出。
+// 正在写出的消息不会被抢占。
+type sendQueue struct {
+	lock    sync.Mutex
+	items   sendHeap
+	seq     uint64
+	size    int                  // 队列容量上限
+	topics  map[string]*sendItem // 各主题尚未写出的最新消息，供 pushLatest 原地覆盖
+	closed  bool                 // 关闭后不再接受新消息
+	notifyC chan struct{}        // 容量为 1，有新消息或队列关闭时通知写循环
+}
+
+func newSendQueue(size int) *sendQueue {
+	return &sendQueue{
+		size:    size,
+		notifyC: make(chan struct{}, 1),
+	}
+}
+
+// pushBarrier 入队一个优先级最低、不受容量限制的屏障项并返回它，其 done 在此前入队的消息全部写出后收到 nil。
+func (q *sendQueue) pushBarrier() (*sendItem, error) {
+	item := &sendItem{priority: math.MinInt, barrier: true, done: make(chan error, 1)}
+	q.lock.Lock()
+	if q.closed {
+		q.lock.Unlock()
+		return nil, ErrSessionClosing
+	}
+	q.seq++
+	item.seq = q.seq
+	heap.Push(&q.items, item)
+	q.lock.Unlock()
+
+	q.notify()
+	return item, nil
+}
+
+// push 将 item 入队；队列已满时返回 ErrSendQueueFull，已关闭时返回 ErrSessionClosing。
+func (q *sendQueue) push(item *sendItem) error {
+	q.lock.Lock()
+	if q.closed {
+		q.lock.Unlock()
+		return ErrSessionClosing
+	}
+	if len(q.items) >= q.size {
+		q.lock.Unlock()
+		return ErrSendQueueFull
+	}
+	q.seq++
+	item.seq = q.seq
+	heap.Push(&q.items, item)
+	q.lock.Unlock()
+
+	q.notify()
+	return nil
+}
+
+// pushLatest 将带主题的 item 入队；同主题已有尚未写出的消息时以 item 的内容（message、kind 与 shared）原地覆盖，
+// 保留其排队位置与优先级且不占用额外容量。
+// 队列已满且无可覆盖的消息时返回 ErrSendQueueFull，已关闭时返回 ErrSessionClosing。
+func (q *sendQueue) pushLatest(item *sendItem) error {
+	q.lock.Lock()
+	if q.closed {
+		q.lock.Unlock()
+		return ErrSessionClosing
+	}
+	if pending, ok := q.topics[item.topic]; ok {
+		pending.message, pending.kind, pending.shared = item.message, item.kind, item.shared
+		q.lock.Unlock()
+		return nil
+	}
+	if len(q.items) >= q.size {
+		q.lock.Unlock()
+		return ErrSendQueueFull
+	}
+	if q.topics == nil {
+		q.topics = make(map[string]*sendItem)
+	}
+	q.seq++
+	item.seq = q.seq
+	heap.Push(&q.items, item)
+	q.topics[item.topic] = item
+	q.lock.Unlock()
+
+	q.notify()
+	return nil
+}
+
+// pop 取出优先级最高的消息；队列为空时 ok 为 false，closed 报告队列是否已关闭。
+func (q *sendQueue) pop() (item *sendItem, ok bool, closed bool) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if q.closed {
+		return nil, false, true
+	}
+	if len(q.items) == 0 {
+		return nil, false, false
+	}
+	item = heap.Pop(&q.items).(*sendItem)
+	if item.topic != "" {
+		// 出队后不再允许覆盖，此后同主题的消息重新排队
+		delete(q.topics, item.topic)
+	}
+	return item, true, false
+}
+
+// len 返回当前排队中的消息数量，不包括 pushBarrier 入队的屏障项。
+func (q *sendQueue) len() int {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	n := len(q.items)
+	for _, item := range q.items {
+		if item.barrier {
+			n--
+		}
+	}
+	return n
+}
+
+// close 关闭队列并丢弃所有未写出的消息，其 done 会收到 ErrSessionClosed；可重复调用。
+func (q *sendQueue) close() {
+	q.lock.Lock()
+	items := q.items
+	q.items = nil
+	q.topics = nil
+	q.closed = true
+	q.lock.Unlock()
+
+	for _, item := range items {
+		item.finish(ErrSessionClosed)
+	}
+	q.notify()
+}
+
+// closeAndTake 关闭队列并按出队顺序取出所有未写出的消息，由调用方负责写出并投递结果；再次调用返回 nil。
+func (q *sendQueue) closeAndTake() []*sendItem {
+	q.lock.Lock()
+	items := make([]*sendItem, 0, len(q.items))
+	for len(q.items) > 0 {
+		items = append(items, heap.Pop(&q.items).(*sendItem))
+	}
+	q.items = nil
+	q.topics = nil
+	q.closed = true
+	q.lock.Unlock()
+
+	q.notify()
+	return items
+}
+
+func (q *sendQueue) notify() {
+	select {
+	case q.notifyC <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,189 @@
+package nexus_test
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kercylan98/vivid/pkg/log"
+
+	nexus "github.com/kercylan98/vivid-nexus"
+	"github.com/kercylan98/vivid-nexus/nexustest"
+)
+
+// TestSendQueueBeforeLaunch 验证启用出站队列时，sessionActor 处理 OnLaunch 之前的 SendWait 不会阻塞。
+func TestSendQueueBeforeLaunch(t *testing.T) {
+	gate := make(chan struct{})
+	defer close(gate)
+	system := &testSystem{logger: log.NewTextLogger(), launchGate: gate}
+	n := newTestNexusOn(t, system, provide(&testActor{}), nexus.WithSendQueue(4))
+
+	memory := nexustest.NewMemorySession("early", nil)
+	if err := n.TakeoverSession(memory); err != nil {
+		t.Fatalf("takeover session: %v", err)
+	}
+	eventually(t, "session registered", func() bool { return len(n.Snapshot()) == 1 })
+
+	errC := make(chan error, 1)
+	go func() { errC <- n.SendWait("early", []byte("hello")) }()
+	select {
+	case err := <-errC:
+		if err != nil {
+			t.Fatalf("send before launch: %v", err)
+		}
+	case <-time.After(testTimeout):
+		t.Fatal("send before launch blocked")
+	}
+	if written := memory.Written(); len(written) != 1 || string(written[0]) != "hello" {
+		t.Fatalf("written %q, want [hello]", written)
+	}
+}
+
+// TestSendQueueOnDisconnected 验证启用出站队列时，OnDisconnected 中发送的消息在关闭底层 Session 前写出。
+func TestSendQueueOnDisconnected(t *testing.T) {
+	n := newTestNexus(t, provide(&testActor{disconnected: func(ctx nexus.SessionContext) {
+		if err := ctx.Send([]byte("bye")); err != nil {
+			t.Errorf("send in OnDisconnected: %v", err)
+		}
+	}}), nexus.WithSendQueue(4))
+
+	memory := nexustest.NewMemorySession("leaving", nil)
+	takeover(t, n, memory)
+	n.Close("leaving")
+	eventually(t, "session closed", memory.Closed)
+	if written := memory.Written(); len(written) != 1 || string(written[0]) != "bye" {
+		t.Fatalf("written %q, want [bye]", written)
+	}
+}
+
+// TestSendLatestCoalesces 验证写循环阻塞期间同主题的 SendLatest 只保留最新的消息，且保留其排队位置。
+func TestSendLatestCoalesces(t *testing.T) {
+	n := newTestNexus(t, provide(&testActor{}), nexus.WithSendQueue(4))
+	slow := &blockingSession{MemorySession: nexustest.NewMemorySession("latest", nil), entered: make(chan struct{}), gate: make(chan struct{})}
+	takeover(t, n, slow)
+
+	// 写循环阻塞在 "first" 的写出中，此后的消息留在队列内
+	if err := n.Send("latest", []byte("first")); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	<-slow.entered
+	for _, message := range []string{"v1", "v2"} {
+		if err := n.(nexus.DeliveryNexus).SendLatest("latest", "price", []byte(message)); err != nil {
+			t.Fatalf("send latest: %v", err)
+		}
+	}
+	if err := n.Send("latest", []byte("last")); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	close(slow.gate)
+
+	want := []string{"first", "v2", "last"}
+	eventually(t, "queued messages written", func() bool { return len(slow.Written()) == len(want) })
+	for i, message := range slow.Written() {
+		if string(message) != want[i] {
+			t.Fatalf("written[%d] = %q, want %q", i, message, want[i])
+		}
+	}
+}
+
+// hangingSession 的 Write 阻塞到 Close 被调用，返回前短暂停顿以模拟写循环收尾，并记录是否有写入返回。
+type hangingSession struct {
+	*nexustest.MemorySession
+	entered  chan struct{}
+	closedC  chan struct{}
+	once     sync.Once
+	enter    sync.Once
+	returned atomic.Bool
+}
+
+func (s *hangingSession) Write(p []byte) (int, error) {
+	s.enter.Do(func() { close(s.entered) })
+	<-s.closedC
+	time.Sleep(20 * time.Millisecond)
+	s.returned.Store(true)
+	return 0, errors.New("connection closed")
+}
+
+func (s *hangingSession) Close() error {
+	s.once.Do(func() { close(s.closedC) })
+	return s.MemorySession.Close()
+}
+
+// TestForceCloseWaitsForWriter 验证 ForceClose 启用出站队列的会话时，写循环在会话 done 关闭前退出。
+func TestForceCloseWaitsForWriter(t *testing.T) {
+	n := newTestNexus(t, provide(&testActor{}), nexus.WithSendQueue(4))
+	hanging := &hangingSession{MemorySession: nexustest.NewMemorySession("hanging", nil), entered: make(chan struct{}), closedC: make(chan struct{})}
+	takeover(t, n, hanging)
+
+	if err := n.Send("hanging", []byte("stuck")); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	<-hanging.entered
+	done := n.Done("hanging")
+	n.ForceClose("hanging")
+	select {
+	case <-done:
+	case <-time.After(testTimeout):
+		t.Fatal("timeout waiting for force close")
+	}
+	if !hanging.returned.Load() {
+		t.Fatal("session done before the queue writer returned from Write")
+	}
+}
+
+// deadlineSession 的 Write 阻塞到 SetWriteDeadline 被调用，并记录 Close 时是否仍有进行中的写入。
+type deadlineSession struct {
+	*nexustest.MemorySession
+	entered       chan struct{}
+	deadlineC     chan struct{}
+	enter, expire sync.Once
+	writing       atomic.Bool
+	closedInWrite atomic.Bool
+}
+
+func (s *deadlineSession) Write(p []byte) (int, error) {
+	s.writing.Store(true)
+	defer s.writing.Store(false)
+	s.enter.Do(func() { close(s.entered) })
+	<-s.deadlineC
+	return 0, errors.New("write deadline exceeded")
+}
+
+func (s *deadlineSession) SetWriteDeadline(deadline time.Time) error {
+	if !deadline.IsZero() {
+		s.expire.Do(func() { close(s.deadlineC) })
+	}
+	return nil
+}
+
+func (s *deadlineSession) Close() error {
+	s.closedInWrite.Store(s.writing.Load())
+	return s.MemorySession.Close()
+}
+
+// TestForceCloseInterruptsWriteBeforeClose 验证底层 Session 支持写超时时，ForceClose 先中断并等待进行中的写入，再关闭底层 Session。
+func TestForceCloseInterruptsWriteBeforeClose(t *testing.T) {
+	n := newTestNexus(t, provide(&testActor{}), nexus.WithSendQueue(4))
+	session := &deadlineSession{MemorySession: nexustest.NewMemorySession("deadline", nil), entered: make(chan struct{}), deadlineC: make(chan struct{})}
+	takeover(t, n, session)
+
+	if err := n.Send("deadline", []byte("stuck")); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	<-session.entered
+	done := n.Done("deadline")
+	n.ForceClose("deadline")
+	select {
+	case <-done:
+	case <-time.After(testTimeout):
+		t.Fatal("timeout waiting for force close")
+	}
+	if !session.Closed() {
+		t.Fatal("session not closed")
+	}
+	if session.closedInWrite.Load() {
+		t.Fatal("session closed while the queue writer was still writing")
+	}
+}
@@ -0,0 +1,52 @@
+package nexus
+
+import (
+	"maps"
+	"slices"
+	"time"
+)
+
+// SessionSnapshot 是某一时刻单个会话状态的只读拷贝，用于调试与诊断。
+//
+// 所有字段均来自 Nexus 自身维护的会话状态，无需额外开启任何配置；
+// Metadata 为接入时元数据的浅拷贝，修改它不会影响会话本身。
+type SessionSnapshot struct {
+	SessionId    string         // 会话 ID
+	ConnectedAt  time.Time      // 会话被接管的时间
+	Uptime       time.Duration  // 截至快照时刻的在线时长
+	LastActivity time.Time      // 最近一次读取或写出数据的时间，尚无数据往来时等于 ConnectedAt
+	BytesIn      uint64         // 累计读取字节数
+	BytesOut     uint64         // 累计写出字节数
+	Ready        bool           // 是否已完成 OnConnected 且未开始关闭
+	Owner        string         // 通过 SetOwner 设置的所有者标识
+	Rooms        []string       // 通过 JoinRoom 加入的房间，未加入任何房间时为 nil
+	Tags         []string       // 通过 SetTags 设置的标签，未设置时为 nil
+	Metadata     map[string]any // 接入时元数据的拷贝，无元数据时为 nil
+}
+
+// Snapshot 返回当前所有托管会话的快照，顺序不固定。
+//
+// 仅在构建切片期间持有一次读锁，返回后的快照与会话后续状态无关。
+func (o *operator) Snapshot() []SessionSnapshot {
+	o.actor.sessionLock.RLock()
+	defer o.actor.sessionLock.RUnlock()
+
+	now := time.Now()
+	snapshots := make([]SessionSnapshot, 0, len(o.actor.sessions))
+	for id, info := range o.actor.sessions {
+		snapshots = append(snapshots, SessionSnapshot{
+			SessionId:    id,
+			ConnectedAt:  info.connectedAt,
+			Uptime:       now.Sub(info.connectedAt),
+			LastActivity: time.Unix(0, info.lastActivity.Load()),
+			BytesIn:      info.bytesIn.Load(),
+			BytesOut:     info.bytesOut.Load(),
+			Ready:        info.ready.Load(),
+			Owner:        info.owner,
+			Rooms:        slices.Collect(maps.Keys(info.rooms)),
+			Tags:         slices.Collect(maps.Keys(info.tags)),
+			Metadata:     maps.Clone(info.metadata),
+		})
+	}
+	return snapshots
+}
@@ -0,0 +1,110 @@
+package nexus
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// defaultStreamChunkSize 为 Options.StreamChunkSize 未设置时 SendStream 的默认分块大小。
+const defaultStreamChunkSize = 32 * 1024
+
+// errStreamReadExpired 表示 SendStream 等待 io.Reader 返回分块时超过了 Options.StreamTimeout，进行中的 Read 仍可能写入分块缓冲区。
+var errStreamReadExpired = errors.New("stream read expired")
+
+// SendStream 将 r 中的数据分块写入指定 ID 的会话，直到 r 返回 io.EOF，返回实际写出的字节数。
+//
+// 整个流在持有 writeLock 的情况下读取与写出，期间该会话的其他写入（Send、Broadcast 及出站队列的写循环）均会等待，
+// 因此流式数据在连接上是连续的，不会被其他消息插入；相应地，缓慢的 r 会阻塞该会话的其他写入，应通过 Options.StreamTimeout 限制其耗时。
+// 配置了 StreamTimeout 时，等待 writeLock、从 r 读取与写出共享同一截止时间，底层 Session 实现了 WriteDeadlineSession 时
+// 写出本身也以该截止时间为写超时（结束后重置为零值），超时返回包装了 ErrSendTimeout 的错误，此时连接上可能已写出部分数据。
+// 分块缓冲区来自池化复用，分块大小由 Options.StreamChunkSize 决定。数据直接写入底层 Session，不经过出站队列与出站分帧。
+// 会话不存在时返回 ErrSessionNotFound，r 为 nil 时返回 ErrNilStreamReader；r 或写入失败时返回已写出的字节数与对应错误。
+func (o *operator) SendStream(sessionId string, r io.Reader) (int64, error) {
+	if !o.launched.Load() {
+		return 0, ErrNotStarted
+	}
+	if r == nil {
+		return 0, ErrNilStreamReader
+	}
+
+	o.actor.sessionLock.RLock()
+	info, ok := o.actor.sessions[sessionId]
+	o.actor.sessionLock.RUnlock()
+	if !ok {
+		return 0, ErrSessionNotFound
+	}
+
+	var deadline time.Time
+	if timeout := o.actor.options.StreamTimeout; timeout > 0 {
+		deadline = time.Now().Add(timeout)
+		if !info.writeLock.LockUntil(deadline) {
+			return 0, ErrSendTimeout
+		}
+		if deadlineSession, ok := unwrapSession[WriteDeadlineSession](info.session()); ok {
+			if err := deadlineSession.SetWriteDeadline(deadline); err == nil {
+				defer deadlineSession.SetWriteDeadline(time.Time{})
+			}
+		}
+	} else {
+		info.writeLock.Lock()
+	}
+	defer info.writeLock.Unlock()
+
+	bufPtr := o.actor.streamBufferPool.Get().(*[]byte)
+	buf := *bufPtr
+
+	var written int64
+	for {
+		n, readErr := readStreamChunk(r, buf, deadline)
+		if errors.Is(readErr, errStreamReadExpired) {
+			// 超时的 Read 仍可能写入 buf，不再归还到池中
+			return written, fmt.Errorf("%w: stream reader did not return in time", ErrSendTimeout)
+		}
+		if n > 0 {
+			w, err := info.writeN(buf[:n])
+			written += int64(w)
+			if err != nil {
+				o.actor.streamBufferPool.Put(bufPtr)
+				if errors.Is(err, os.ErrDeadlineExceeded) {
+					err = fmt.Errorf("%w: %w", ErrSendTimeout, err)
+				}
+				return written, err
+			}
+		}
+		if readErr != nil {
+			o.actor.streamBufferPool.Put(bufPtr)
+			if errors.Is(readErr, io.EOF) {
+				return written, nil
+			}
+			return written, readErr
+		}
+	}
+}
+
+// readStreamChunk 从 r 读取一个分块到 buf；deadline 为零值时直接读取，否则在独立 goroutine 中读取并最多等待到 deadline，
+// 到期仍未返回时返回 errStreamReadExpired。
+func readStreamChunk(r io.Reader, buf []byte, deadline time.Time) (int, error) {
+	if deadline.IsZero() {
+		return r.Read(buf)
+	}
+	type result struct {
+		n   int
+		err error
+	}
+	resultC := make(chan result, 1)
+	go func() {
+		n, err := r.Read(buf)
+		resultC <- result{n, err}
+	}()
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+	select {
+	case res := <-resultC:
+		return res.n, res.err
+	case <-timer.C:
+		return 0, errStreamReadExpired
+	}
+}
@@ -0,0 +1,104 @@
+package nexus_test
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	nexus "github.com/kercylan98/vivid-nexus"
+	"github.com/kercylan98/vivid-nexus/nexustest"
+)
+
+// streamResult 是在独立 goroutine 中调用 SendStream 的结果。
+type streamResult struct {
+	written int64
+	err     error
+}
+
+// sendStreamAsync 在独立 goroutine 中调用 SendStream 并返回接收结果的通道。
+func sendStreamAsync(n nexus.Nexus, sessionId string, r io.Reader) <-chan streamResult {
+	resultC := make(chan streamResult, 1)
+	go func() {
+		written, err := n.(nexus.DeliveryNexus).SendStream(sessionId, r)
+		resultC <- streamResult{written, err}
+	}()
+	return resultC
+}
+
+// TestSendStreamIsContiguous 验证 SendStream 在整个流期间持有 writeLock，并发的写入不会插入到两个分块之间。
+func TestSendStreamIsContiguous(t *testing.T) {
+	n := newTestNexus(t, provide(&testActor{}))
+	memory := nexustest.NewMemorySession("stream", nil)
+	takeover(t, n, memory)
+
+	pr, pw := io.Pipe()
+	resultC := sendStreamAsync(n, "stream", pr)
+	if _, err := pw.Write([]byte("chunk-1")); err != nil {
+		t.Fatalf("write chunk: %v", err)
+	}
+	eventually(t, "first chunk written", func() bool { return len(memory.Written()) == 1 })
+
+	// 此时 SendStream 阻塞在读取下一个分块上，并发的 Send 需等待流结束
+	sendC := make(chan error, 1)
+	go func() { sendC <- n.SendWait("stream", []byte("message")) }()
+	select {
+	case err := <-sendC:
+		t.Fatalf("send completed in the middle of a stream: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if _, err := pw.Write([]byte("chunk-2")); err != nil {
+		t.Fatalf("write chunk: %v", err)
+	}
+	_ = pw.Close()
+
+	r := <-resultC
+	if r.err != nil || r.written != int64(len("chunk-1")+len("chunk-2")) {
+		t.Fatalf("SendStream = (%d, %v), want (14, nil)", r.written, r.err)
+	}
+	if err := <-sendC; err != nil {
+		t.Fatalf("send after stream: %v", err)
+	}
+	var written []string
+	for _, data := range memory.Written() {
+		written = append(written, string(data))
+	}
+	if len(written) != 3 || written[0] != "chunk-1" || written[1] != "chunk-2" || written[2] != "message" {
+		t.Fatalf("written %q, want [chunk-1 chunk-2 message]", written)
+	}
+}
+
+// TestSendStreamTimeout 验证配置 StreamTimeout 后，阻塞的 io.Reader 使 SendStream 以 ErrSendTimeout 结束并释放 writeLock。
+func TestSendStreamTimeout(t *testing.T) {
+	const timeout = 50 * time.Millisecond
+	n := newTestNexus(t, provide(&testActor{}), nexus.WithStreamTimeout(timeout))
+	memory := nexustest.NewMemorySession("stream", nil)
+	takeover(t, n, memory)
+
+	pr, pw := io.Pipe()
+	defer pw.Close()
+	start := time.Now()
+	var r streamResult
+	select {
+	case r = <-sendStreamAsync(n, "stream", pr):
+	case <-time.After(testTimeout):
+		t.Fatal("SendStream not bounded by the stream timeout")
+	}
+	if !errors.Is(r.err, nexus.ErrSendTimeout) || r.written != 0 {
+		t.Fatalf("SendStream = (%d, %v), want (0, ErrSendTimeout)", r.written, r.err)
+	}
+	if elapsed := time.Since(start); elapsed < timeout {
+		t.Fatalf("SendStream returned after %s, want at least %s", elapsed, timeout)
+	}
+	if err := n.SendWait("stream", []byte("message")); err != nil {
+		t.Fatalf("send after stream timeout: %v", err)
+	}
+}
+
+func TestSendStreamNilReader(t *testing.T) {
+	n := newTestNexus(t, provide(&testActor{}))
+	takeover(t, n, nexustest.NewMemorySession("stream", nil))
+	if _, err := n.(nexus.DeliveryNexus).SendStream("stream", nil); !errors.Is(err, nexus.ErrNilStreamReader) {
+		t.Fatalf("SendStream(nil) = %v, want ErrNilStreamReader", err)
+	}
+}
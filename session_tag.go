@@ -0,0 +1,74 @@
+package nexus
+
+import (
+	"maps"
+	"slices"
+)
+
+// setTags 以 tags 覆盖 info 的标签集合并同步反向索引；若 info 已不再被托管则无操作。
+func (o *operator) setTags(info *sessionInfo, tags []string) {
+	o.actor.sessionLock.Lock()
+	defer o.actor.sessionLock.Unlock()
+
+	id := info.GetSessionId()
+	if o.actor.sessions[id] != info {
+		return
+	}
+	o.actor.removeTagIndex(id, info)
+	info.tags = nil
+	for _, tag := range tags {
+		if info.tags == nil {
+			info.tags = make(map[string]struct{}, len(tags))
+		}
+		info.tags[tag] = struct{}{}
+		ids, ok := o.actor.tags[tag]
+		if !ok {
+			ids = make(map[string]struct{})
+			o.actor.tags[tag] = ids
+		}
+		ids[id] = struct{}{}
+	}
+}
+
+// BroadcastTag 向带有 tag 的所有会话推送 message，标签不存在时无操作。errorHandler 语义同 SendTo。
+//
+// 标签通过 SessionContext.SetTags 设置，适用于按静态属性（如 "premium"、"beta"）定向广播、无需维护房间成员关系的场景。
+func (o *operator) BroadcastTag(tag string, message []byte, errorHandler ...SendErrorHandler) {
+	o.actor.sessionLock.RLock()
+	ids := o.actor.tags[tag]
+	sessionIds := make([]string, 0, len(ids))
+	for id := range ids {
+		sessionIds = append(sessionIds, id)
+	}
+	o.actor.sessionLock.RUnlock()
+
+	o.sendTo(sessionIds, message, errorHandler)
+}
+
+// TagSize 返回带有 tag 的会话数量，标签不存在时返回 0。
+func (o *operator) TagSize(tag string) int {
+	o.actor.sessionLock.RLock()
+	defer o.actor.sessionLock.RUnlock()
+
+	return len(o.actor.tags[tag])
+}
+
+// Tags 返回当前至少被一个会话使用的所有标签，顺序不固定。
+func (o *operator) Tags() []string {
+	o.actor.sessionLock.RLock()
+	defer o.actor.sessionLock.RUnlock()
+
+	return slices.Collect(maps.Keys(o.actor.tags))
+}
+
+// removeTagIndex 将会话从其所有标签的反向索引中移除，调用方需持有 sessionLock 写锁。
+func (n *Actor) removeTagIndex(id string, info *sessionInfo) {
+	for tag := range info.tags {
+		if ids, ok := n.tags[tag]; ok {
+			delete(ids, id)
+			if len(ids) == 0 {
+				delete(n.tags, tag)
+			}
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package nexus
+
+import "slices"
+
+// ShutdownOrder 描述 Nexus 关闭所有会话时的顺序。
+type ShutdownOrder uint8
+
+const (
+	// ShutdownOrderNone 不指定顺序，按 map 遍历的随机顺序关闭。
+	ShutdownOrderNone ShutdownOrder = iota
+	// ShutdownOrderOldestFirst 按接管时间从早到晚关闭。
+	ShutdownOrderOldestFirst
+	// ShutdownOrderNewestFirst 按接管时间从晚到早关闭。
+	ShutdownOrderNewestFirst
+)
+
+// sort 按 order 对 infos 原地排序，ShutdownOrderNone 时保持原顺序。
+func (order ShutdownOrder) sort(infos []*sessionInfo) {
+	switch order {
+	case ShutdownOrderOldestFirst:
+		slices.SortStableFunc(infos, func(a, b *sessionInfo) int {
+			return a.connectedAt.Compare(b.connectedAt)
+		})
+	case ShutdownOrderNewestFirst:
+		slices.SortStableFunc(infos, func(a, b *sessionInfo) int {
+			return b.connectedAt.Compare(a.connectedAt)
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package nexus
+
+import "context"
+
+// Started 报告 Nexus Actor 是否已启动（收到 OnLaunch），启动前依赖会话的操作会返回 ErrNotStarted。
+func (o *operator) Started() bool {
+	return o.launched.Load()
+}
+
+// WaitStarted 阻塞直到 Nexus Actor 启动或 ctx 结束，已启动时立即返回 nil，ctx 结束时返回 ctx.Err()。
+//
+// 用于解决应用启动时接入层（如 HTTP 服务）先于 Nexus Actor 就绪的时序竞争：在开始接受连接前调用，
+// 可避免 TakeoverSession、Send 等操作因尚未启动而返回 ErrNotStarted。
+func (o *operator) WaitStarted(ctx context.Context) error {
+	select {
+	case <-o.startedC:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// markStarted 在 Nexus Actor 首次启动时唤醒所有 WaitStarted 的调用方，重复调用无操作。
+func (o *operator) markStarted() {
+	o.launched.Store(true)
+	o.startOnce.Do(func() {
+		close(o.startedC)
+	})
+}
@@ -0,0 +1,17 @@
+package nexus
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// jitter 在 d 的基础上按 ±fraction 的比例随机抖动，用于错开大量会话的内部定时器。
+//
+// fraction 小于等于 0 或 d 小于等于 0 时原样返回 d。
+func jitter(d time.Duration, fraction float64) time.Duration {
+	if fraction <= 0 || d <= 0 {
+		return d
+	}
+	delta := (rand.Float64()*2 - 1) * fraction * float64(d)
+	return d + time.Duration(delta)
+}